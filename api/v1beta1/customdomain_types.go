@@ -26,12 +26,28 @@ import (
 type CustomDomainSpec struct {
 	// LoadBalancerProvider is the load balancer provider for this domain.
 	LoadBalancerProvider *string `json:"loadBalancerProvider,omitempty"`
+	// TargetLoadBalancerProvider is the load balancer provider this domain is migrating to.
+	// +optional
+	TargetLoadBalancerProvider *string `json:"targetLoadBalancerProvider,omitempty"`
 	// VerificationKey is the domain verification token key.
 	VerificationKey *string `json:"verificationKey,omitempty"`
+	// PreviousVerificationKey is the verification key before last rotation.
+	// +optional
+	PreviousVerificationKey *string `json:"previousVerificationKey,omitempty"`
+	// PreviousVerificationKeyExpireAt is the time that previous verification key is no longer accepted.
+	// +optional
+	PreviousVerificationKeyExpireAt *metav1.Time `json:"previousVerificationKeyExpireAt,omitempty"`
+	// RotateVerificationKeyAt is the time that verification key should be rotated,
+	// it is cleared once the key is rotated.
+	// +optional
+	RotateVerificationKeyAt *metav1.Time `json:"rotateVerificationKeyAt,omitempty"`
 	// Registrations are registrations from apps.
 	Registrations []corev1.ObjectReference `json:"registrations,omitempty"`
 	// OwnerApp is the app which the registration is accepted
 	OwnerApp *string `json:"ownerApp,omitempty"`
+	// Region is the home region of the owner app.
+	// +optional
+	Region *string `json:"region,omitempty"`
 }
 
 // CustomDomainDNSRecord is a DNS record associated with the domain
@@ -50,12 +66,19 @@ type CustomDomainConditionType string
 const (
 	// DomainLoadBalancerProvisioned indicates the required domain resource is provisioned.
 	DomainLoadBalancerProvisioned CustomDomainRegistrationConditionType = "LoadBalancerProvisioned"
+	// DomainLoadBalancerMigrating indicates the domain is migrating to the target load balancer provider.
+	DomainLoadBalancerMigrating CustomDomainConditionType = "LoadBalancerMigrating"
+	// DomainDNSRecordsPublished indicates the load balancer DNS records are published into managed zone.
+	DomainDNSRecordsPublished CustomDomainConditionType = "DNSRecordsPublished"
 )
 
 // CustomDomainStatusLoadBalancer defines the status of the domain load balancer
 type CustomDomainStatusLoadBalancer struct {
 	// Provider is the provider of this load balancer
 	Provider string `json:"provider"`
+	// Region is the region served by this load balancer, empty if the provider is not region-aware
+	// +optional
+	Region string `json:"region,omitempty"`
 	// DNSRecords are DNS records that should be associated with the domain
 	// +optional
 	DNSRecords []CustomDomainDNSRecord `json:"dnsRecords,omitempty"`
@@ -70,6 +93,15 @@ type CustomDomainStatus struct {
 	Conditions []api.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 	// LoadBalancer is the status of the domain load balancer
 	LoadBalancer *CustomDomainStatusLoadBalancer `json:"loadBalancer,omitempty"`
+	// TargetLoadBalancer is the status of the load balancer this domain is migrating to
+	// +optional
+	TargetLoadBalancer *CustomDomainStatusLoadBalancer `json:"targetLoadBalancer,omitempty"`
+	// LoadBalancerCutOverTime is the time DNS records are observed pointing to the target load balancer
+	// +optional
+	LoadBalancerCutOverTime *metav1.Time `json:"loadBalancerCutOverTime,omitempty"`
+	// LastVerificationKeyRotationTime is the time that last verification key rotation is performed
+	// +optional
+	LastVerificationKeyRotationTime *metav1.Time `json:"lastVerificationKeyRotationTime,omitempty"`
 }
 
 // +kubebuilder:object:root=true
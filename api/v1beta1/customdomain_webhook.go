@@ -66,7 +66,15 @@ func (r *CustomDomain) validate(old *CustomDomain) error {
 	if old != nil &&
 		old.Spec.LoadBalancerProvider != nil &&
 		(r.Spec.LoadBalancerProvider == nil || *old.Spec.LoadBalancerProvider != *r.Spec.LoadBalancerProvider) {
-		errs = append(errs, field.Invalid(field.NewPath("spec", "loadBalancerProvider"), r.Name, "load balancer provider cannot be changed"))
+		// load balancer provider can only be changed by completing migration,
+		// after the controller observed DNS records pointing to the target
+		migrated := old.Spec.TargetLoadBalancerProvider != nil &&
+			r.Spec.LoadBalancerProvider != nil &&
+			*old.Spec.TargetLoadBalancerProvider == *r.Spec.LoadBalancerProvider &&
+			old.Status.LoadBalancerCutOverTime != nil
+		if !migrated {
+			errs = append(errs, field.Invalid(field.NewPath("spec", "loadBalancerProvider"), r.Name, "load balancer provider cannot be changed, use targetLoadBalancerProvider to migrate"))
+		}
 	}
 
 	if len(errs) != 0 {
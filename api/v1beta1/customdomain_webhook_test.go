@@ -0,0 +1,50 @@
+package v1beta1_test
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+)
+
+func TestValidateLoadBalancerProviderChange(t *testing.T) {
+	newDomain := func(provider, target *string, cutOver bool) *domainv1beta1.CustomDomain {
+		d := &domainv1beta1.CustomDomain{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-app.test"},
+			Spec: domainv1beta1.CustomDomainSpec{
+				LoadBalancerProvider:       provider,
+				TargetLoadBalancerProvider: target,
+			},
+		}
+		if cutOver {
+			now := metav1.Now()
+			d.Status.LoadBalancerCutOverTime = &now
+		}
+		return d
+	}
+	source := pointer.StringPtr("source")
+	target := pointer.StringPtr("target")
+
+	cases := []struct {
+		name string
+		old  *domainv1beta1.CustomDomain
+		new  *domainv1beta1.CustomDomain
+		ok   bool
+	}{
+		{"initial", newDomain(nil, nil, false), newDomain(source, nil, false), true},
+		{"unchanged", newDomain(source, nil, false), newDomain(source, nil, false), true},
+		{"start migration", newDomain(source, nil, false), newDomain(source, target, false), true},
+		{"without migration", newDomain(source, nil, false), newDomain(target, nil, false), false},
+		{"before cut-over", newDomain(source, target, false), newDomain(target, nil, false), false},
+		{"after cut-over", newDomain(source, target, true), newDomain(target, nil, true), true},
+		{"to other provider", newDomain(source, target, true), newDomain(pointer.StringPtr("other"), nil, true), false},
+	}
+	for _, c := range cases {
+		err := c.new.ValidateUpdate(c.old)
+		if (err == nil) != c.ok {
+			t.Errorf("unexpected validation result of %s: %v", c.name, err)
+		}
+	}
+}
@@ -28,21 +28,72 @@ type CustomDomainConfig struct {
 	BackendServicePort int `json:"backendServicePort"`
 	// CertSecretName of the name of Secret storing custom TLS certificate
 	CertSecretName *string `json:"certSecretName,omitempty"`
+	// CertIssuer overrides the cert-manager issuer of TLS certificate
+	// +optional
+	CertIssuer *CertIssuerReference `json:"certIssuer,omitempty"`
 	// RedirectToURL is where to redirect the user
 	RedirectToURL *string `json:"redirectToURL,omitempty"`
 }
 
+// CertIssuerReference is a reference to cert-manager issuer
+type CertIssuerReference struct {
+	// Kind is the kind of issuer, defaults to Issuer in namespace of the registration
+	// +kubebuilder:validation:Enum=Issuer;ClusterIssuer
+	// +optional
+	Kind string `json:"kind,omitempty"`
+	// Name is the name of issuer
+	Name string `json:"name"`
+}
+
+// VerificationMethod is a method of verifying domain ownership
+// +kubebuilder:validation:Enum=txt;cname;http
+type VerificationMethod string
+
+const (
+	// VerificationMethodTXT verifies the domain using a TXT DNS record.
+	VerificationMethodTXT VerificationMethod = "txt"
+	// VerificationMethodCNAME verifies the domain using a CNAME DNS record.
+	VerificationMethodCNAME VerificationMethod = "cname"
+	// VerificationMethodHTTP verifies the domain using a well-known HTTP URL.
+	// It can pass only while the domain has no other registrations.
+	VerificationMethodHTTP VerificationMethod = "http"
+)
+
+// VerificationRecordScope is the domain verification DNS records are placed under
+// +kubebuilder:validation:Enum=root;exact
+type VerificationRecordScope string
+
+const (
+	// VerificationRecordScopeRoot places verification DNS records under the registrable root domain.
+	VerificationRecordScopeRoot VerificationRecordScope = "root"
+	// VerificationRecordScopeExact places verification DNS records under the exact domain.
+	VerificationRecordScopeExact VerificationRecordScope = "exact"
+)
+
 // CustomDomainRegistrationSpec defines the desired state of CustomDomainRegistration
 type CustomDomainRegistrationSpec struct {
 	// DomainName is the custom domain name registered with the app.
 	DomainName string `json:"domainName"`
 	// DomainConfig is the configuration of custom domain
 	DomainConfig CustomDomainConfig `json:"domainConfig"`
+	// VerificationMethod is the method used to verify the domain, defaults to txt
+	// +optional
+	VerificationMethod *VerificationMethod `json:"verificationMethod,omitempty"`
+	// VerificationRecordScope is the domain verification DNS records are placed under, defaults to root
+	// +optional
+	VerificationRecordScope *VerificationRecordScope `json:"verificationRecordScope,omitempty"`
+	// Region is the home region of the app serving the domain, defaults to
+	// the region label of the namespace.
+	// +optional
+	Region *string `json:"region,omitempty"`
 	// VerifyAt is the time that next verification should be performed
 	// +optional
 	VerifyAt *metav1.Time `json:"verifyAt,omitempty"`
 }
 
+// RegionLabel is the namespace label specifying the home region of the app.
+const RegionLabel = "domain.skygear.io/region"
+
 // CustomDomainRegistrationConditionType is a valid CustomDomainRegistration condition type
 type CustomDomainRegistrationConditionType string
 
@@ -51,12 +102,27 @@ const (
 	RegistrationVerified CustomDomainRegistrationConditionType = "Verified"
 	// RegistrationAccepted indicates the registration is accepted.
 	RegistrationAccepted CustomDomainRegistrationConditionType = "Accepted"
+	// RegistrationDNSConfigured indicates the domain resolves to the provisioned load balancer.
+	RegistrationDNSConfigured CustomDomainRegistrationConditionType = "DNSConfigured"
 	// RegistrationCertReady indicates TLS certificate for the registration is ready.
 	RegistrationCertReady CustomDomainRegistrationConditionType = "CertReady"
 	// RegistrationIngressReady indicates ingress for the registration is ready.
 	RegistrationIngressReady CustomDomainRegistrationConditionType = "IngressReady"
+	// RegistrationCertExpiringSoon indicates TLS certificate for the registration is expiring soon.
+	RegistrationCertExpiringSoon CustomDomainRegistrationConditionType = "CertExpiringSoon"
 )
 
+// CustomDomainVerificationResult is the verification result from a resolver
+type CustomDomainVerificationResult struct {
+	// Resolver is the resolver performing the verification
+	Resolver string `json:"resolver"`
+	// Verified indicates whether the resolver verified the domain
+	Verified bool `json:"verified"`
+	// Message is the reason of failed verification
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
 // CustomDomainRegistrationStatus defines the observed state of CustomDomainRegistration
 type CustomDomainRegistrationStatus struct {
 	// Current state of registration.
@@ -67,12 +133,39 @@ type CustomDomainRegistrationStatus struct {
 	// DNSRecords are DNS records that should be associated with the domain
 	// +optional
 	DNSRecords []CustomDomainDNSRecord `json:"dnsRecords,omitempty"`
+	// PublishedVerificationRecords are verification DNS records published into managed zone
+	// +optional
+	PublishedVerificationRecords []CustomDomainDNSRecord `json:"publishedVerificationRecords,omitempty"`
 	// LastVerificationTime is the time that last verification is performed
 	// +optional
 	LastVerificationTime *metav1.Time `json:"lastVerificationTime,omitempty"`
+	// LastSuccessfulVerificationTime is the time that last successful verification is performed
+	// +optional
+	LastSuccessfulVerificationTime *metav1.Time `json:"lastSuccessfulVerificationTime,omitempty"`
+	// LastDNSCheckTime is the time that DNS records of the domain are last checked
+	// +optional
+	LastDNSCheckTime *metav1.Time `json:"lastDNSCheckTime,omitempty"`
+	// VerificationResults are results of last verification from each resolver
+	// +optional
+	VerificationResults []CustomDomainVerificationResult `json:"verificationResults,omitempty"`
 	// CertSecretName is the name of TLS certificate secret
 	// +optional
 	CertSecretName *string `json:"certSecretName,omitempty"`
+	// Certificate is the status of TLS certificate in use
+	// +optional
+	Certificate *CustomDomainRegistrationStatusCertificate `json:"certificate,omitempty"`
+}
+
+// CustomDomainRegistrationStatusCertificate defines the status of TLS certificate
+type CustomDomainRegistrationStatusCertificate struct {
+	// Issuer is the distinguished name of certificate issuer
+	Issuer string `json:"issuer"`
+	// SerialNumber is the hex-encoded serial number of certificate
+	SerialNumber string `json:"serialNumber"`
+	// NotBefore is the time that certificate becomes valid
+	NotBefore metav1.Time `json:"notBefore"`
+	// NotAfter is the time that certificate expires
+	NotAfter metav1.Time `json:"notAfter"`
 }
 
 // +kubebuilder:object:root=true
@@ -0,0 +1,85 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/skygeario/k8s-controller/api"
+)
+
+// LoadBalancerClassSpec defines the desired state of LoadBalancerClass
+type LoadBalancerClassSpec struct {
+	// Type is the type of load balancer provider, e.g. static-ip, cname, service, dedicated-ip, cdn or plugin
+	Type string `json:"type"`
+	// Parameters are the parameters of load balancer provider, in same format as configuration file
+	// +optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Parameters *runtime.RawExtension `json:"parameters,omitempty"`
+	// AllowApexDomains indicates whether this class may serve apex domains
+	// +optional
+	AllowApexDomains bool `json:"allowApexDomains,omitempty"`
+	// AllowSubdomains indicates whether this class may serve subdomains
+	// +optional
+	AllowSubdomains bool `json:"allowSubdomains,omitempty"`
+	// Default indicates whether this class is selected for domains without load balancer provider
+	// +optional
+	Default bool `json:"default,omitempty"`
+}
+
+// LoadBalancerClassConditionType is a valid LoadBalancerClass condition type
+type LoadBalancerClassConditionType string
+
+const (
+	// LoadBalancerClassReady indicates the load balancer provider is configured.
+	LoadBalancerClassReady LoadBalancerClassConditionType = "Ready"
+)
+
+// LoadBalancerClassStatus defines the observed state of LoadBalancerClass
+type LoadBalancerClassStatus struct {
+	// Current state of load balancer class.
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	Conditions []api.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+
+// LoadBalancerClass is the Schema for the loadbalancerclasses API
+type LoadBalancerClass struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   LoadBalancerClassSpec   `json:"spec,omitempty"`
+	Status LoadBalancerClassStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// LoadBalancerClassList contains a list of LoadBalancerClass
+type LoadBalancerClassList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LoadBalancerClass `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&LoadBalancerClass{}, &LoadBalancerClassList{})
+}
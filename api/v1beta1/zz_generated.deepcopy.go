@@ -25,6 +25,21 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertIssuerReference) DeepCopyInto(out *CertIssuerReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertIssuerReference.
+func (in *CertIssuerReference) DeepCopy() *CertIssuerReference {
+	if in == nil {
+		return nil
+	}
+	out := new(CertIssuerReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CustomDomain) DeepCopyInto(out *CustomDomain) {
 	*out = *in
@@ -60,6 +75,11 @@ func (in *CustomDomainConfig) DeepCopyInto(out *CustomDomainConfig) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.CertIssuer != nil {
+		in, out := &in.CertIssuer, &out.CertIssuer
+		*out = new(CertIssuerReference)
+		**out = **in
+	}
 	if in.RedirectToURL != nil {
 		in, out := &in.RedirectToURL, &out.RedirectToURL
 		*out = new(string)
@@ -187,6 +207,21 @@ func (in *CustomDomainRegistrationList) DeepCopyObject() runtime.Object {
 func (in *CustomDomainRegistrationSpec) DeepCopyInto(out *CustomDomainRegistrationSpec) {
 	*out = *in
 	in.DomainConfig.DeepCopyInto(&out.DomainConfig)
+	if in.VerificationMethod != nil {
+		in, out := &in.VerificationMethod, &out.VerificationMethod
+		*out = new(VerificationMethod)
+		**out = **in
+	}
+	if in.VerificationRecordScope != nil {
+		in, out := &in.VerificationRecordScope, &out.VerificationRecordScope
+		*out = new(VerificationRecordScope)
+		**out = **in
+	}
+	if in.Region != nil {
+		in, out := &in.Region, &out.Region
+		*out = new(string)
+		**out = **in
+	}
 	if in.VerifyAt != nil {
 		in, out := &in.VerifyAt, &out.VerifyAt
 		*out = (*in).DeepCopy()
@@ -218,15 +253,38 @@ func (in *CustomDomainRegistrationStatus) DeepCopyInto(out *CustomDomainRegistra
 		*out = make([]CustomDomainDNSRecord, len(*in))
 		copy(*out, *in)
 	}
+	if in.PublishedVerificationRecords != nil {
+		in, out := &in.PublishedVerificationRecords, &out.PublishedVerificationRecords
+		*out = make([]CustomDomainDNSRecord, len(*in))
+		copy(*out, *in)
+	}
 	if in.LastVerificationTime != nil {
 		in, out := &in.LastVerificationTime, &out.LastVerificationTime
 		*out = (*in).DeepCopy()
 	}
+	if in.LastSuccessfulVerificationTime != nil {
+		in, out := &in.LastSuccessfulVerificationTime, &out.LastSuccessfulVerificationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastDNSCheckTime != nil {
+		in, out := &in.LastDNSCheckTime, &out.LastDNSCheckTime
+		*out = (*in).DeepCopy()
+	}
+	if in.VerificationResults != nil {
+		in, out := &in.VerificationResults, &out.VerificationResults
+		*out = make([]CustomDomainVerificationResult, len(*in))
+		copy(*out, *in)
+	}
 	if in.CertSecretName != nil {
 		in, out := &in.CertSecretName, &out.CertSecretName
 		*out = new(string)
 		**out = **in
 	}
+	if in.Certificate != nil {
+		in, out := &in.Certificate, &out.Certificate
+		*out = new(CustomDomainRegistrationStatusCertificate)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomDomainRegistrationStatus.
@@ -239,6 +297,23 @@ func (in *CustomDomainRegistrationStatus) DeepCopy() *CustomDomainRegistrationSt
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomDomainRegistrationStatusCertificate) DeepCopyInto(out *CustomDomainRegistrationStatusCertificate) {
+	*out = *in
+	in.NotBefore.DeepCopyInto(&out.NotBefore)
+	in.NotAfter.DeepCopyInto(&out.NotAfter)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomDomainRegistrationStatusCertificate.
+func (in *CustomDomainRegistrationStatusCertificate) DeepCopy() *CustomDomainRegistrationStatusCertificate {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomDomainRegistrationStatusCertificate)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CustomDomainSpec) DeepCopyInto(out *CustomDomainSpec) {
 	*out = *in
@@ -247,11 +322,29 @@ func (in *CustomDomainSpec) DeepCopyInto(out *CustomDomainSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.TargetLoadBalancerProvider != nil {
+		in, out := &in.TargetLoadBalancerProvider, &out.TargetLoadBalancerProvider
+		*out = new(string)
+		**out = **in
+	}
 	if in.VerificationKey != nil {
 		in, out := &in.VerificationKey, &out.VerificationKey
 		*out = new(string)
 		**out = **in
 	}
+	if in.PreviousVerificationKey != nil {
+		in, out := &in.PreviousVerificationKey, &out.PreviousVerificationKey
+		*out = new(string)
+		**out = **in
+	}
+	if in.PreviousVerificationKeyExpireAt != nil {
+		in, out := &in.PreviousVerificationKeyExpireAt, &out.PreviousVerificationKeyExpireAt
+		*out = (*in).DeepCopy()
+	}
+	if in.RotateVerificationKeyAt != nil {
+		in, out := &in.RotateVerificationKeyAt, &out.RotateVerificationKeyAt
+		*out = (*in).DeepCopy()
+	}
 	if in.Registrations != nil {
 		in, out := &in.Registrations, &out.Registrations
 		*out = make([]v1.ObjectReference, len(*in))
@@ -262,6 +355,11 @@ func (in *CustomDomainSpec) DeepCopyInto(out *CustomDomainSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.Region != nil {
+		in, out := &in.Region, &out.Region
+		*out = new(string)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomDomainSpec.
@@ -289,6 +387,19 @@ func (in *CustomDomainStatus) DeepCopyInto(out *CustomDomainStatus) {
 		*out = new(CustomDomainStatusLoadBalancer)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.TargetLoadBalancer != nil {
+		in, out := &in.TargetLoadBalancer, &out.TargetLoadBalancer
+		*out = new(CustomDomainStatusLoadBalancer)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LoadBalancerCutOverTime != nil {
+		in, out := &in.LoadBalancerCutOverTime, &out.LoadBalancerCutOverTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastVerificationKeyRotationTime != nil {
+		in, out := &in.LastVerificationKeyRotationTime, &out.LastVerificationKeyRotationTime
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomDomainStatus.
@@ -320,3 +431,192 @@ func (in *CustomDomainStatusLoadBalancer) DeepCopy() *CustomDomainStatusLoadBala
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomDomainVerificationResult) DeepCopyInto(out *CustomDomainVerificationResult) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomDomainVerificationResult.
+func (in *CustomDomainVerificationResult) DeepCopy() *CustomDomainVerificationResult {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomDomainVerificationResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPAddressAllocation) DeepCopyInto(out *IPAddressAllocation) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPAddressAllocation.
+func (in *IPAddressAllocation) DeepCopy() *IPAddressAllocation {
+	if in == nil {
+		return nil
+	}
+	out := new(IPAddressAllocation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IPAddressAllocation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPAddressAllocationList) DeepCopyInto(out *IPAddressAllocationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]IPAddressAllocation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPAddressAllocationList.
+func (in *IPAddressAllocationList) DeepCopy() *IPAddressAllocationList {
+	if in == nil {
+		return nil
+	}
+	out := new(IPAddressAllocationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IPAddressAllocationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPAddressAllocationSpec) DeepCopyInto(out *IPAddressAllocationSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPAddressAllocationSpec.
+func (in *IPAddressAllocationSpec) DeepCopy() *IPAddressAllocationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IPAddressAllocationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerClass) DeepCopyInto(out *LoadBalancerClass) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerClass.
+func (in *LoadBalancerClass) DeepCopy() *LoadBalancerClass {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerClass)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LoadBalancerClass) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerClassList) DeepCopyInto(out *LoadBalancerClassList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LoadBalancerClass, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerClassList.
+func (in *LoadBalancerClassList) DeepCopy() *LoadBalancerClassList {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerClassList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LoadBalancerClassList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerClassSpec) DeepCopyInto(out *LoadBalancerClassSpec) {
+	*out = *in
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerClassSpec.
+func (in *LoadBalancerClassSpec) DeepCopy() *LoadBalancerClassSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerClassSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerClassStatus) DeepCopyInto(out *LoadBalancerClassStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]api.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerClassStatus.
+func (in *LoadBalancerClassStatus) DeepCopy() *LoadBalancerClassStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerClassStatus)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,49 @@
+package controllers_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/tls"
+	"github.com/skygeario/k8s-controller/pkg/domain/tls/acme"
+	"github.com/skygeario/k8s-controller/pkg/util/condition"
+)
+
+var _ = Describe("Certificate renewal", func() {
+	const namespace = "renew"
+	const domain = "renew.test"
+
+	It("Should keep current certificate ready when renewal fails", func() {
+		createRegistration(namespace, domain)
+		configureDNS(namespace, domain)
+		requestVerification(namespace, domain)
+		certReady := registrationCondition(namespace, domain, domainv1beta1.RegistrationCertReady)
+		Eventually(certReady, testTimeout, testInterval).Should(Equal(metav1.ConditionTrue))
+		certReadyReason := func() string {
+			reg := getRegistration(namespace, domain)
+			cond := condition.Lookup(reg.Status.Conditions, string(domainv1beta1.RegistrationCertReady))
+			Expect(cond).NotTo(BeNil())
+			return cond.Reason
+		}
+
+		By("failing renewal")
+		tlsProvider.SetRenewalError(namespace, domain, &tls.ProvisionError{
+			Reason: acme.ReasonOrderBackoff,
+			Err:    errors.New("ACME order failed"),
+		})
+		Eventually(certReadyReason, testTimeout, testInterval).Should(Equal(acme.ReasonOrderBackoff))
+		Expect(certReady()).To(Equal(metav1.ConditionTrue))
+		Expect(getRegistration(namespace, domain).Status.CertSecretName).NotTo(BeNil())
+
+		By("renewing certificate")
+		tlsProvider.SetRenewalError(namespace, domain, nil)
+		Eventually(certReadyReason, testTimeout, testInterval).Should(BeEmpty())
+		Expect(certReady()).To(Equal(metav1.ConditionTrue))
+
+		deleteRegistration(namespace, domain)
+	})
+})
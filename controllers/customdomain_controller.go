@@ -17,20 +17,30 @@ package controllers
 
 import (
 	"context"
+	"time"
 
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/skygeario/k8s-controller/api"
 	domain "github.com/skygeario/k8s-controller/api"
 	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/ingress"
 	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer"
+	"github.com/skygeario/k8s-controller/pkg/domain/publish/externaldns"
+	"github.com/skygeario/k8s-controller/pkg/domain/verification"
 	"github.com/skygeario/k8s-controller/pkg/util/condition"
 	"github.com/skygeario/k8s-controller/pkg/util/deadline"
 	"github.com/skygeario/k8s-controller/pkg/util/finalizer"
@@ -42,6 +52,10 @@ type LoadBalancer interface {
 	Release(ctx context.Context, domain *domainv1beta1.CustomDomain) (ok bool, err error)
 }
 
+type DNSChecker interface {
+	CheckDNSRecords(ctx context.Context, domain string, records []domainv1beta1.CustomDomainDNSRecord) error
+}
+
 // CustomDomainReconciler reconciles a CustomDomain object
 type CustomDomainReconciler struct {
 	client.Client
@@ -49,11 +63,24 @@ type CustomDomainReconciler struct {
 	Scheme                   *runtime.Scheme
 	Now                      func() metav1.Time
 	LoadBalancer             LoadBalancer
+	LoadBalancerService      *types.NamespacedName
+	DNSChecker               DNSChecker
+	DNSPublisher             DNSPublisher
+	ExternalDNS              *externaldns.Provider
+	LoadBalancerEvents       <-chan event.GenericEvent
 	VerificationKeyGenerator func() string
+	IngressProvider          ingress.Provider
+	HTTPVerification         *verification.HTTPConfig
 }
 
 // +kubebuilder:rbac:groups=domain.skygear.io,resources=customdomains,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=domain.skygear.io,resources=customdomains/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups=domain.skygear.io,resources=ipaddressallocations,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups=domain.skygear.io,resources=loadbalancerclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=externaldns.k8s.io,resources=dnsendpoints,verbs=get;list;watch;create;update;patch;delete
 
 func (r *CustomDomainReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	ctx := context.Background()
@@ -90,13 +117,20 @@ func (r *CustomDomainReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error
 			return ctrl.Result{Requeue: true}, nil
 		}
 
+		err = r.updateRegion(ctx, &d)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+
 		provisioned, err := r.provisionLoadBalancer(ctx, &d)
 		if err != nil {
 			conditions = append(conditions, api.Condition{
 				Type:    string(domainv1beta1.DomainLoadBalancerProvisioned),
 				Status:  metav1.ConditionUnknown,
+				Reason:  loadbalancer.ReasonOf(err),
 				Message: err.Error(),
 			})
+			requeueDeadline.Set(r.Now().Add(PollInterval))
 		} else {
 			conditions = append(conditions, api.Condition{
 				Type:   string(domainv1beta1.DomainLoadBalancerProvisioned),
@@ -107,14 +141,85 @@ func (r *CustomDomainReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error
 			}
 		}
 
+		migrating, message, err := r.migrateLoadBalancer(ctx, &d)
+		if err != nil {
+			conditions = append(conditions, api.Condition{
+				Type:    string(domainv1beta1.DomainLoadBalancerMigrating),
+				Status:  metav1.ConditionUnknown,
+				Reason:  loadbalancer.ReasonOf(err),
+				Message: err.Error(),
+			})
+			requeueDeadline.Set(r.Now().Add(PollInterval))
+		} else if migrating {
+			conditions = append(conditions, api.Condition{
+				Type:    string(domainv1beta1.DomainLoadBalancerMigrating),
+				Status:  metav1.ConditionTrue,
+				Message: message,
+			})
+			requeueDeadline.Set(r.Now().Add(DNSCheckInterval))
+		}
+
+		if r.DNSPublisher != nil && r.DNSPublisher.Manages(d.Name) {
+			published, err := r.publishDNSRecords(ctx, &d)
+			if err != nil {
+				conditions = append(conditions, api.Condition{
+					Type:    string(domainv1beta1.DomainDNSRecordsPublished),
+					Status:  metav1.ConditionUnknown,
+					Message: err.Error(),
+				})
+				requeueDeadline.Set(r.Now().Add(PollInterval))
+			} else {
+				conditions = append(conditions, api.Condition{
+					Type:   string(domainv1beta1.DomainDNSRecordsPublished),
+					Status: condition.ToStatus(published),
+				})
+			}
+		}
+
 		err = r.processRegistrations(ctx, &d)
 		if err != nil {
 			return ctrl.Result{}, err
 		}
 
+		rotateTime, err := r.rotateVerificationKey(ctx, &d)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if rotateTime != nil {
+			requeueDeadline.Set(*rotateTime)
+		}
+
+		err = r.updateVerificationIngress(ctx, &d)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+
+		err = r.updateDNSEndpoint(ctx, &d)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+
 	} else {
 		doFinalize = true
 
+		if r.DNSPublisher != nil && r.DNSPublisher.Manages(d.Name) {
+			err := r.unpublishDNSRecords(ctx, &d)
+			if err != nil {
+				doFinalize = false
+				conditions = append(conditions, api.Condition{
+					Type:    string(domainv1beta1.DomainDNSRecordsPublished),
+					Status:  metav1.ConditionUnknown,
+					Message: err.Error(),
+				})
+				requeueDeadline.Set(r.Now().Add(PollInterval))
+			} else {
+				conditions = append(conditions, api.Condition{
+					Type:   string(domainv1beta1.DomainDNSRecordsPublished),
+					Status: metav1.ConditionFalse,
+				})
+			}
+		}
+
 		released, err := r.releaseLoadBalancer(ctx, &d)
 		if err != nil {
 			doFinalize = false
@@ -150,10 +255,100 @@ func (r *CustomDomainReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error
 }
 
 func (r *CustomDomainReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&domainv1beta1.CustomDomain{}).
-		Owns(&domainv1beta1.CustomDomainRegistration{}).
-		Complete(r)
+		Owns(&domainv1beta1.CustomDomainRegistration{})
+
+	if r.ExternalDNS != nil {
+		b = b.Owns(r.ExternalDNS.MakeEmptyDNSEndpoint())
+	}
+
+	if r.LoadBalancerService != nil {
+		b = b.Watches(
+			&source.Kind{Type: &corev1.Service{}},
+			&handler.EnqueueRequestsFromMapFunc{
+				ToRequests: handler.ToRequestsFunc(func(o handler.MapObject) []ctrl.Request {
+					if o.Meta.GetNamespace() != r.LoadBalancerService.Namespace ||
+						o.Meta.GetName() != r.LoadBalancerService.Name {
+						return nil
+					}
+
+					var domains domainv1beta1.CustomDomainList
+					if err := r.List(context.Background(), &domains); err != nil {
+						r.Log.Error(err, "failed to list custom domains")
+						return nil
+					}
+					reqs := make([]ctrl.Request, len(domains.Items))
+					for i, d := range domains.Items {
+						reqs[i] = ctrl.Request{NamespacedName: types.NamespacedName{Name: d.Name}}
+					}
+					return reqs
+				}),
+			},
+		)
+	}
+
+	if r.LoadBalancerEvents != nil {
+		b = b.Watches(
+			&source.Channel{Source: r.LoadBalancerEvents},
+			&handler.EnqueueRequestsFromMapFunc{
+				ToRequests: handler.ToRequestsFunc(func(o handler.MapObject) []ctrl.Request {
+					var domains domainv1beta1.CustomDomainList
+					if err := r.List(context.Background(), &domains); err != nil {
+						r.Log.Error(err, "failed to list custom domains")
+						return nil
+					}
+					reqs := make([]ctrl.Request, len(domains.Items))
+					for i, d := range domains.Items {
+						reqs[i] = ctrl.Request{NamespacedName: types.NamespacedName{Name: d.Name}}
+					}
+					return reqs
+				}),
+			},
+		)
+	}
+
+	b = b.Watches(
+		&source.Kind{Type: &domainv1beta1.LoadBalancerClass{}},
+		&handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(func(o handler.MapObject) []ctrl.Request {
+				var domains domainv1beta1.CustomDomainList
+				if err := r.List(context.Background(), &domains); err != nil {
+					r.Log.Error(err, "failed to list custom domains")
+					return nil
+				}
+				var reqs []ctrl.Request
+				for _, d := range domains.Items {
+					if d.Spec.LoadBalancerProvider != nil && *d.Spec.LoadBalancerProvider == o.Meta.GetName() {
+						reqs = append(reqs, ctrl.Request{NamespacedName: types.NamespacedName{Name: d.Name}})
+					}
+				}
+				return reqs
+			}),
+		},
+	)
+
+	b = b.Watches(
+		&source.Kind{Type: &corev1.Namespace{}},
+		&handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(func(o handler.MapObject) []ctrl.Request {
+				var domains domainv1beta1.CustomDomainList
+				if err := r.List(context.Background(), &domains); err != nil {
+					r.Log.Error(err, "failed to list custom domains")
+					return nil
+				}
+				var reqs []ctrl.Request
+				for _, d := range domains.Items {
+					if d.Spec.OwnerApp != nil && *d.Spec.OwnerApp == o.Meta.GetName() {
+						reqs = append(reqs, ctrl.Request{NamespacedName: types.NamespacedName{Name: d.Name}})
+					}
+				}
+				return reqs
+			}),
+		},
+	)
+
+	return b.Complete(r)
 }
 
 func (r *CustomDomainReconciler) validateRegistrations(ctx context.Context, d *domainv1beta1.CustomDomain) error {
@@ -192,7 +387,12 @@ func (r *CustomDomainReconciler) validateRegistrations(ctx context.Context, d *d
 }
 
 func (r *CustomDomainReconciler) provisionLoadBalancer(ctx context.Context, d *domainv1beta1.CustomDomain) (bool, error) {
-	providerType, result, err := r.LoadBalancer.Provision(ctx, d)
+	if d.Status.LoadBalancerCutOverTime != nil {
+		// Source load balancer is being released after cut-over.
+		return true, nil
+	}
+
+	providerType, result, err := r.LoadBalancer.Provision(ctx, makeMigrationSource(d))
 	if err != nil {
 		return false, err
 	}
@@ -204,11 +404,216 @@ func (r *CustomDomainReconciler) provisionLoadBalancer(ctx context.Context, d *d
 		}
 	}
 
+	d.Status.LoadBalancer = makeLoadBalancerStatus(providerType, result)
+
+	return result != nil, nil
+}
+
+func (r *CustomDomainReconciler) releaseLoadBalancer(ctx context.Context, d *domainv1beta1.CustomDomain) (bool, error) {
+	released, err := r.LoadBalancer.Release(ctx, makeMigrationSource(d))
+	if err != nil || !released {
+		return released, err
+	}
+
+	if d.Spec.TargetLoadBalancerProvider != nil || d.Status.TargetLoadBalancer != nil {
+		return r.LoadBalancer.Release(ctx, makeMigrationTarget(d))
+	}
+	return true, nil
+}
+
+// migrateLoadBalancer provisions the target load balancer while the source
+// load balancer keeps serving, and releases the source load balancer once DNS
+// records of the domain are observed pointing to the target. Changing region
+// of a region-aware load balancer is migrated in the same way.
+func (r *CustomDomainReconciler) migrateLoadBalancer(ctx context.Context, d *domainv1beta1.CustomDomain) (migrating bool, message string, err error) {
+	if d.Spec.TargetLoadBalancerProvider == nil && !isRegionChanging(d) {
+		d.Status.TargetLoadBalancer = nil
+		d.Status.LoadBalancerCutOverTime = nil
+		return false, "", nil
+	}
+
+	if d.Spec.TargetLoadBalancerProvider != nil &&
+		d.Spec.LoadBalancerProvider != nil &&
+		*d.Spec.LoadBalancerProvider == *d.Spec.TargetLoadBalancerProvider {
+		status := d.Status
+		patch := client.MergeFrom(d.DeepCopy())
+		d.Spec.TargetLoadBalancerProvider = nil
+		if err := r.Patch(ctx, d, patch); err != nil {
+			return false, "", err
+		}
+		d.Status = status
+		if !isRegionChanging(d) {
+			d.Status.TargetLoadBalancer = nil
+			d.Status.LoadBalancerCutOverTime = nil
+			return false, "", nil
+		}
+		// Only region is changing, migrate within the current provider.
+	}
+
+	target := makeMigrationTarget(d)
+	providerType, result, err := r.LoadBalancer.Provision(ctx, target)
+	if err != nil {
+		return true, "", err
+	}
+	targetLoadBalancer := makeLoadBalancerStatus(providerType, result)
+	d.Status.TargetLoadBalancer = targetLoadBalancer
+	if result == nil {
+		return true, "provisioning target load balancer", nil
+	}
+
+	if d.Status.LoadBalancerCutOverTime == nil {
+		err = func() error {
+			checkCtx, cancel := context.WithTimeout(ctx, VerificationTimeout)
+			defer cancel()
+			return r.DNSChecker.CheckDNSRecords(checkCtx, d.Name, targetLoadBalancer.DNSRecords)
+		}()
+		if err != nil {
+			return true, "waiting for DNS records pointing to target load balancer", nil
+		}
+
+		// Persist cut-over before releasing, so that source load balancer
+		// would not be provisioned again.
+		now := r.Now()
+		d.Status.LoadBalancerCutOverTime = &now
+		if err := r.Status().Update(ctx, d); err != nil {
+			return true, "", err
+		}
+	}
+
+	released, err := r.LoadBalancer.Release(ctx, makeMigrationSource(d))
+	if err != nil {
+		return true, "", err
+	} else if !released {
+		return true, "releasing source load balancer", nil
+	}
+
+	if d.Spec.TargetLoadBalancerProvider != nil {
+		patch := client.MergeFrom(d.DeepCopy())
+		d.Spec.LoadBalancerProvider = d.Spec.TargetLoadBalancerProvider
+		d.Spec.TargetLoadBalancerProvider = nil
+		if err := r.Patch(ctx, d, patch); err != nil {
+			return true, "", err
+		}
+	}
+	d.Status.LoadBalancer = targetLoadBalancer
+	d.Status.TargetLoadBalancer = nil
+	d.Status.LoadBalancerCutOverTime = nil
+	return false, "", nil
+}
+
+func (r *CustomDomainReconciler) publishDNSRecords(ctx context.Context, d *domainv1beta1.CustomDomain) (bool, error) {
+	loadBalancer := expectedLoadBalancer(d)
+	if loadBalancer == nil {
+		return false, nil
+	}
+
+	records := managedDNSRecords(r.DNSPublisher, d.Name, loadBalancer.DNSRecords)
+	if len(records) == 0 {
+		return false, nil
+	}
+	if err := r.DNSPublisher.Publish(ctx, d.Name, records); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *CustomDomainReconciler) unpublishDNSRecords(ctx context.Context, d *domainv1beta1.CustomDomain) error {
+	for _, loadBalancer := range []*domainv1beta1.CustomDomainStatusLoadBalancer{d.Status.LoadBalancer, d.Status.TargetLoadBalancer} {
+		if loadBalancer == nil {
+			continue
+		}
+		records := managedDNSRecords(r.DNSPublisher, d.Name, loadBalancer.DNSRecords)
+		if len(records) == 0 {
+			continue
+		}
+		if err := r.DNSPublisher.Unpublish(ctx, d.Name, records); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func makeMigrationTarget(d *domainv1beta1.CustomDomain) *domainv1beta1.CustomDomain {
+	target := d.DeepCopy()
+	if target.Spec.TargetLoadBalancerProvider != nil {
+		target.Spec.LoadBalancerProvider = target.Spec.TargetLoadBalancerProvider
+	}
+	return target
+}
+
+// makeMigrationSource returns the domain as served by the current load
+// balancer, which keeps its region until the new region is cut over.
+func makeMigrationSource(d *domainv1beta1.CustomDomain) *domainv1beta1.CustomDomain {
+	if d.Status.LoadBalancer == nil || d.Status.LoadBalancer.Region == "" {
+		return d
+	}
+	source := d.DeepCopy()
+	source.Spec.Region = pointer.StringPtr(d.Status.LoadBalancer.Region)
+	return source
+}
+
+func isRegionChanging(d *domainv1beta1.CustomDomain) bool {
+	return d.Spec.Region != nil &&
+		d.Status.LoadBalancer != nil &&
+		d.Status.LoadBalancer.Region != "" &&
+		d.Status.LoadBalancer.Region != *d.Spec.Region
+}
+
+// updateRegion updates region of the domain to home region of the owner app.
+func (r *CustomDomainReconciler) updateRegion(ctx context.Context, d *domainv1beta1.CustomDomain) error {
+	if d.Spec.OwnerApp == nil {
+		return nil
+	}
+
+	var region *string
+	for _, ref := range d.Spec.Registrations {
+		if ref.Namespace != *d.Spec.OwnerApp {
+			continue
+		}
+		var reg domainv1beta1.CustomDomainRegistration
+		if err := r.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, &reg); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		region = reg.Spec.Region
+		break
+	}
+
+	if region == nil {
+		var ns corev1.Namespace
+		if err := r.Get(ctx, types.NamespacedName{Name: *d.Spec.OwnerApp}, &ns); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		if label, ok := ns.Labels[domainv1beta1.RegionLabel]; ok {
+			region = &label
+		}
+	}
+
+	if region == nil || (d.Spec.Region != nil && *d.Spec.Region == *region) {
+		return nil
+	}
+
+	status := d.Status
+	patch := client.MergeFrom(d.DeepCopy())
+	d.Spec.Region = region
+	if err := r.Patch(ctx, d, patch); err != nil {
+		return err
+	}
+	d.Status = status
+	return nil
+}
+
+func makeLoadBalancerStatus(providerType string, result *loadbalancer.ProvisionResult) *domainv1beta1.CustomDomainStatusLoadBalancer {
 	loadBalancer := &domainv1beta1.CustomDomainStatusLoadBalancer{
 		Provider: providerType,
 	}
 
 	if result != nil {
+		if result.Region != nil {
+			loadBalancer.Region = *result.Region
+		}
 		dnsRecords := make([]domainv1beta1.CustomDomainDNSRecord, len(result.DNSRecords))
 		for i, r := range result.DNSRecords {
 			dnsRecords[i] = domainv1beta1.CustomDomainDNSRecord{
@@ -219,14 +624,7 @@ func (r *CustomDomainReconciler) provisionLoadBalancer(ctx context.Context, d *d
 		}
 		loadBalancer.DNSRecords = dnsRecords
 	}
-
-	d.Status.LoadBalancer = loadBalancer
-
-	return result != nil, nil
-}
-
-func (r *CustomDomainReconciler) releaseLoadBalancer(ctx context.Context, d *domainv1beta1.CustomDomain) (bool, error) {
-	return r.LoadBalancer.Release(ctx, d)
+	return loadBalancer
 }
 
 func (r *CustomDomainReconciler) processRegistrations(ctx context.Context, d *domainv1beta1.CustomDomain) error {
@@ -294,3 +692,137 @@ func (r *CustomDomainReconciler) processRegistrations(ctx context.Context, d *do
 
 	return nil
 }
+
+func (r *CustomDomainReconciler) rotateVerificationKey(ctx context.Context, d *domainv1beta1.CustomDomain) (requeueTime *time.Time, err error) {
+	now := r.Now()
+	now = metav1.Unix(now.Unix(), 0) // truncate to seconds
+
+	if d.Spec.RotateVerificationKeyAt != nil {
+		rotateTime := d.Spec.RotateVerificationKeyAt.Time
+		if !now.After(rotateTime) {
+			return &rotateTime, nil
+		}
+
+		// Rotation request is cleared in the same patch, so that the key is
+		// not rotated again even if status update fails.
+		expireAt := metav1.NewTime(now.Add(VerificationKeyRotationPeriod))
+		status := d.Status
+		patch := client.MergeFrom(d.DeepCopy())
+		d.Spec.PreviousVerificationKey = d.Spec.VerificationKey
+		d.Spec.PreviousVerificationKeyExpireAt = &expireAt
+		d.Spec.VerificationKey = pointer.StringPtr(r.VerificationKeyGenerator())
+		d.Spec.RotateVerificationKeyAt = nil
+		if err := r.Patch(ctx, d, patch); err != nil {
+			return nil, err
+		}
+		d.Status = status
+		d.Status.LastVerificationKeyRotationTime = &now
+	}
+
+	if d.Spec.PreviousVerificationKeyExpireAt != nil {
+		expireTime := d.Spec.PreviousVerificationKeyExpireAt.Time
+		if !now.After(expireTime) {
+			return &expireTime, nil
+		}
+
+		status := d.Status
+		patch := client.MergeFrom(d.DeepCopy())
+		d.Spec.PreviousVerificationKey = nil
+		d.Spec.PreviousVerificationKeyExpireAt = nil
+		if err := r.Patch(ctx, d, patch); err != nil {
+			return nil, err
+		}
+		d.Status = status
+	}
+
+	return nil, nil
+}
+
+func (r *CustomDomainReconciler) updateVerificationIngress(ctx context.Context, d *domainv1beta1.CustomDomain) error {
+	if r.HTTPVerification == nil {
+		return nil
+	}
+
+	needIngress := false
+	for _, ref := range d.Spec.Registrations {
+		var reg domainv1beta1.CustomDomainRegistration
+		if err := r.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, &reg); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		if verification.MethodOf(&reg) == domainv1beta1.VerificationMethodHTTP {
+			needIngress = true
+			break
+		}
+	}
+
+	ingress, err := r.IngressProvider.MakeVerificationIngress(d, r.HTTPVerification.Namespace, networkingv1beta1.IngressBackend{
+		ServiceName: r.HTTPVerification.ServiceName,
+		ServicePort: intstr.FromInt(r.HTTPVerification.ServicePort),
+	})
+	if err != nil {
+		return err
+	}
+
+	existingIngress := &networkingv1beta1.Ingress{}
+	if err = r.Get(ctx, types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}, existingIngress); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	switch {
+	case apierrors.IsNotFound(err) && needIngress:
+		return r.Create(ctx, ingress)
+	case apierrors.IsNotFound(err):
+		return nil
+	case !needIngress:
+		return r.Delete(ctx, existingIngress)
+	default:
+		existingIngress = existingIngress.DeepCopy()
+		existingIngress.Labels = ingress.Labels
+		existingIngress.Annotations = ingress.Annotations
+		existingIngress.Spec = ingress.Spec
+		return r.Update(ctx, existingIngress)
+	}
+}
+
+func (r *CustomDomainReconciler) updateDNSEndpoint(ctx context.Context, d *domainv1beta1.CustomDomain) error {
+	if r.ExternalDNS == nil {
+		return nil
+	}
+
+	var records []domainv1beta1.CustomDomainDNSRecord
+	if loadBalancer := expectedLoadBalancer(d); loadBalancer != nil && r.ExternalDNS.Manages(d.Name) {
+		records = loadBalancer.DNSRecords
+	}
+	needEndpoint := len(records) > 0
+
+	endpoint, err := r.ExternalDNS.MakeDNSEndpoint(d, records)
+	if err != nil {
+		return err
+	}
+
+	existingEndpoint := r.ExternalDNS.MakeEmptyDNSEndpoint()
+	if err = r.Get(ctx, types.NamespacedName{Namespace: endpoint.GetNamespace(), Name: endpoint.GetName()}, existingEndpoint); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	switch {
+	case apierrors.IsNotFound(err) && needEndpoint:
+		return r.Create(ctx, endpoint)
+	case apierrors.IsNotFound(err):
+		return nil
+	case !needEndpoint:
+		return r.Delete(ctx, existingEndpoint)
+	default:
+		existingEndpoint = existingEndpoint.DeepCopy()
+		existingEndpoint.SetOwnerReferences(endpoint.GetOwnerReferences())
+		existingEndpoint.Object["spec"] = endpoint.Object["spec"]
+		return r.Update(ctx, existingEndpoint)
+	}
+}
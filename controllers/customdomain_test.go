@@ -144,9 +144,11 @@ var _ = Describe("Custom Domain", func() {
 				return statusOf(namespace, domain)
 			}
 
-			domainChecker.Records["_skygear.my-app.test"] = []string{
+			domainChecker.SetRecords("my-app.test", "127.0.0.1")
+			domainChecker.SetRecords("sub.my-app.test", "127.0.0.1")
+			domainChecker.SetRecords("_skygear.my-app.test",
 				"bf46fcae092bcfdbbfb6900e0c343c4447cc284a98e0e3cf49df0470e90085ab",
-			}
+			)
 			Expect(verify("app1", "my-app.test")).To(MatchError("verification DNS record not found"))
 			Expect(verify("app2", "my-app.test")).To(Succeed())
 			Expect(verify("app2", "sub.my-app.test")).To(Succeed())
@@ -155,9 +157,9 @@ var _ = Describe("Custom Domain", func() {
 			domainRegOld := &domainv1beta1.CustomDomainRegistration{}
 			Expect(k8sClient.Get(ctx, n, domainRegOld)).To(Succeed())
 
-			domainChecker.Records["_skygear.my-app.test"] = []string{
+			domainChecker.SetRecords("_skygear.my-app.test",
 				"c4fe13c3968005a8d8fddd37fd2738450b131c6881a501e62d8393660664330d",
-			}
+			)
 			Expect(verify("app1", "my-app.test")).To(Succeed())
 			Expect(verify("app2", "my-app.test")).To(MatchError("verification DNS record not found"))
 			Expect(verify("app2", "sub.my-app.test")).To(MatchError("verification DNS record not found"))
@@ -168,10 +170,10 @@ var _ = Describe("Custom Domain", func() {
 			interval := domainRegNew.Status.LastVerificationTime.Sub(domainRegOld.Status.LastVerificationTime.Time)
 			Expect(interval).To(BeNumerically(">=", controllers.VerificationCooldown))
 
-			domainChecker.Records["_skygear.my-app.test"] = []string{
+			domainChecker.SetRecords("_skygear.my-app.test",
 				"bf46fcae092bcfdbbfb6900e0c343c4447cc284a98e0e3cf49df0470e90085ab",
 				"c4fe13c3968005a8d8fddd37fd2738450b131c6881a501e62d8393660664330d",
-			}
+			)
 			Expect(verify("app1", "my-app.test")).To(Succeed())
 			Expect(verify("app2", "my-app.test")).To(Succeed())
 			Expect(verify("app2", "sub.my-app.test")).To(Succeed())
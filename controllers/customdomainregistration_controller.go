@@ -17,15 +17,18 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -36,7 +39,6 @@ import (
 	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
 	"github.com/skygeario/k8s-controller/pkg/domain/ingress"
 	"github.com/skygeario/k8s-controller/pkg/domain/tls"
-	"github.com/skygeario/k8s-controller/pkg/domain/verification"
 	"github.com/skygeario/k8s-controller/pkg/util/condition"
 	"github.com/skygeario/k8s-controller/pkg/util/deadline"
 	"github.com/skygeario/k8s-controller/pkg/util/finalizer"
@@ -52,6 +54,12 @@ type IngressProvider interface {
 	MakeIngress(reg *domainv1beta1.CustomDomainRegistration) (*networkingv1beta1.Ingress, error)
 }
 
+type DomainVerifier interface {
+	MakeDNSRecords(reg *domainv1beta1.CustomDomainRegistration, token string) ([]domainv1beta1.CustomDomainDNSRecord, error)
+	VerifyDomain(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration, token string) ([]domainv1beta1.CustomDomainVerificationResult, error)
+	CheckDNSRecords(ctx context.Context, domain string, records []domainv1beta1.CustomDomainDNSRecord) error
+}
+
 // CustomDomainRegistrationReconciler reconciles a CustomDomainRegistration object
 type CustomDomainRegistrationReconciler struct {
 	client.Client
@@ -60,13 +68,19 @@ type CustomDomainRegistrationReconciler struct {
 
 	Now                        func() metav1.Time
 	VerificationTokenGenerator func(key, nonce string) string
-	DomainVerifier             func(ctx context.Context, domain, token string) error
+	DomainVerifier             DomainVerifier
 	TLSProvider                TLSProvider
 	IngressProvider            ingress.Provider
+	DNSPublisher               DNSPublisher
+	Recorder                   record.EventRecorder
+	CertExpiryThresholds       []time.Duration
 }
 
 // +kubebuilder:rbac:groups=domain.skygear.io,resources=customdomainregistrations,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=domain.skygear.io,resources=customdomainregistrations/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 func (r *CustomDomainRegistrationReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	ctx := context.Background()
@@ -128,10 +142,63 @@ func (r *CustomDomainRegistrationReconciler) Reconcile(req ctrl.Request) (ctrl.R
 			})
 		}
 
-		var certSecretName *string
+		dnsConfigured := false
 		if accepted {
-			tlsResult, err := r.TLSProvider.Provision(ctx, &reg)
+			var nextCheckTime time.Time
+			dnsConfigured, nextCheckTime, err = r.checkDNSConfigured(ctx, &reg)
 			if err != nil {
+				conditions = append(conditions, api.Condition{
+					Type:    string(domainv1beta1.RegistrationDNSConfigured),
+					Status:  condition.ToStatus(dnsConfigured),
+					Message: err.Error(),
+				})
+			} else {
+				conditions = append(conditions, api.Condition{
+					Type:   string(domainv1beta1.RegistrationDNSConfigured),
+					Status: condition.ToStatus(dnsConfigured),
+				})
+			}
+			requeueDeadline.Set(nextCheckTime)
+		}
+
+		var certSecretName *string
+		if accepted && !dnsConfigured {
+			// Wait for DNS configuration before requesting certificate
+			conditions = append(conditions, api.Condition{
+				Type:    string(domainv1beta1.RegistrationCertReady),
+				Status:  metav1.ConditionFalse,
+				Message: "domain DNS is not configured",
+			})
+			certSecretName = reg.Status.CertSecretName
+		} else if accepted {
+			tlsResult, err := r.TLSProvider.Provision(ctx, &reg)
+			if tlsResult != nil && err != nil {
+				// Renewal failed; current certificate is served until it
+				// expires, so it remains ready.
+				reason := tls.ReasonOf(err)
+				if reason == "" {
+					reason = "RenewalFailed"
+				}
+				renewalFailed := api.Condition{
+					Type:    string(domainv1beta1.RegistrationCertReady),
+					Status:  metav1.ConditionTrue,
+					Reason:  reason,
+					Message: fmt.Sprintf("certificate renewal failed: %s", err),
+				}
+				old := condition.Lookup(reg.Status.Conditions, renewalFailed.Type)
+				if old == nil || old.Message != renewalFailed.Message {
+					r.Recorder.Eventf(&reg, corev1.EventTypeWarning, "CertRenewalFailed", "%s", err)
+				}
+				conditions = append(conditions, renewalFailed)
+			} else if reason := tls.ReasonOf(err); reason != "" {
+				// Errors with reason are known failures, e.g. invalid certificate.
+				conditions = append(conditions, api.Condition{
+					Type:    string(domainv1beta1.RegistrationCertReady),
+					Status:  metav1.ConditionFalse,
+					Reason:  reason,
+					Message: err.Error(),
+				})
+			} else if err != nil {
 				conditions = append(conditions, api.Condition{
 					Type:    string(domainv1beta1.RegistrationCertReady),
 					Status:  metav1.ConditionUnknown,
@@ -147,6 +214,9 @@ func (r *CustomDomainRegistrationReconciler) Reconcile(req ctrl.Request) (ctrl.R
 				requeueDeadline.Set(r.Now().Add(PollInterval))
 			} else {
 				certSecretName = &tlsResult.CertSecretName
+				if tlsResult.RequeueTime != nil {
+					requeueDeadline.Set(*tlsResult.RequeueTime)
+				}
 			}
 		} else {
 			released, err := r.TLSProvider.Release(ctx, &reg)
@@ -168,6 +238,20 @@ func (r *CustomDomainRegistrationReconciler) Reconcile(req ctrl.Request) (ctrl.R
 		}
 		reg.Status.CertSecretName = certSecretName
 
+		expiringSoon, err := r.checkCertificateExpiry(ctx, &reg)
+		if err != nil {
+			conditions = append(conditions, api.Condition{
+				Type:    string(domainv1beta1.RegistrationCertExpiringSoon),
+				Status:  metav1.ConditionUnknown,
+				Message: err.Error(),
+			})
+		} else if expiringSoon != nil {
+			conditions = append(conditions, expiringSoon.condition)
+			if expiringSoon.requeueTime != nil {
+				requeueDeadline.Set(*expiringSoon.requeueTime)
+			}
+		}
+
 		if accepted {
 			ok, err := r.updateIngress(ctx, &reg)
 			if err != nil {
@@ -249,6 +333,29 @@ func (r *CustomDomainRegistrationReconciler) SetupWithManager(mgr ctrl.Manager)
 				}),
 			},
 		).
+		Watches(
+			&source.Kind{Type: &corev1.Secret{}},
+			&handler.EnqueueRequestsFromMapFunc{
+				ToRequests: handler.ToRequestsFunc(func(o handler.MapObject) []ctrl.Request {
+					var regs domainv1beta1.CustomDomainRegistrationList
+					if err := r.List(context.Background(), &regs, client.InNamespace(o.Meta.GetNamespace())); err != nil {
+						r.Log.Error(err, "failed to list custom domain registrations")
+						return nil
+					}
+					var reqs []ctrl.Request
+					for _, reg := range regs.Items {
+						certSecretName := reg.Spec.DomainConfig.CertSecretName
+						if certSecretName == nil {
+							certSecretName = reg.Status.CertSecretName
+						}
+						if certSecretName != nil && *certSecretName == o.Meta.GetName() {
+							reqs = append(reqs, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: reg.Namespace, Name: reg.Name}})
+						}
+					}
+					return reqs
+				}),
+			},
+		).
 		Complete(r)
 }
 
@@ -305,6 +412,10 @@ func (r *CustomDomainRegistrationReconciler) unregisterDomain(ctx context.Contex
 		return false, err
 	}
 
+	if err := r.unpublishVerificationRecords(ctx, reg, &domain); err != nil {
+		return false, err
+	}
+
 	if slice.ContainsObjectReference(domain.Spec.Registrations, reg) {
 		patch := client.MergeFrom(domain.DeepCopy())
 		domain.Spec.Registrations = slice.RemoveObjectReference(domain.Spec.Registrations, reg)
@@ -317,6 +428,77 @@ func (r *CustomDomainRegistrationReconciler) unregisterDomain(ctx context.Contex
 	return !registered, nil
 }
 
+func (r *CustomDomainRegistrationReconciler) unpublishVerificationRecords(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration, domain *domainv1beta1.CustomDomain) error {
+	if r.DNSPublisher == nil {
+		return nil
+	}
+
+	var records []domainv1beta1.CustomDomainDNSRecord
+	for _, key := range []*string{domain.Spec.VerificationKey, domain.Spec.PreviousVerificationKey} {
+		if key == nil {
+			continue
+		}
+		token := r.VerificationTokenGenerator(*key, string(reg.Namespace))
+		verificationRecords, err := r.DomainVerifier.MakeDNSRecords(reg, token)
+		if err != nil {
+			return err
+		}
+		records = append(records, managedDNSRecords(r.DNSPublisher, reg.Spec.DomainName, verificationRecords)...)
+	}
+
+	records = append(records, reg.Status.PublishedVerificationRecords...)
+
+	if len(records) == 0 {
+		return nil
+	}
+	return r.DNSPublisher.Unpublish(ctx, reg.Spec.DomainName, records)
+}
+
+// publishVerificationRecords publishes verification records into managed
+// zone. Publishing records makes the registration pass verification, so only
+// records of the accepted app, or apps allowed by the zone, are published.
+func (r *CustomDomainRegistrationReconciler) publishVerificationRecords(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration, domain *domainv1beta1.CustomDomain, verificationRecords []domainv1beta1.CustomDomainDNSRecord) error {
+	if r.DNSPublisher == nil {
+		return nil
+	}
+
+	var records []domainv1beta1.CustomDomainDNSRecord
+	accepted := domain.Spec.OwnerApp != nil && *domain.Spec.OwnerApp == reg.Namespace
+	if accepted || r.DNSPublisher.AllowsApp(reg.Spec.DomainName, reg.Namespace) {
+		records = managedDNSRecords(r.DNSPublisher, reg.Spec.DomainName, verificationRecords)
+	}
+	if equality.Semantic.DeepEqual(records, reg.Status.PublishedVerificationRecords) {
+		return nil
+	}
+
+	var staleRecords []domainv1beta1.CustomDomainDNSRecord
+	for _, published := range reg.Status.PublishedVerificationRecords {
+		stale := true
+		for _, record := range records {
+			if record == published {
+				stale = false
+				break
+			}
+		}
+		if stale {
+			staleRecords = append(staleRecords, published)
+		}
+	}
+	if len(staleRecords) > 0 {
+		if err := r.DNSPublisher.Unpublish(ctx, reg.Spec.DomainName, staleRecords); err != nil {
+			return err
+		}
+	}
+	if len(records) > 0 {
+		if err := r.DNSPublisher.Publish(ctx, reg.Spec.DomainName, records); err != nil {
+			return err
+		}
+	}
+
+	reg.Status.PublishedVerificationRecords = records
+	return nil
+}
+
 func (r *CustomDomainRegistrationReconciler) verifyDomainIfNeeded(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (requeueTime *time.Time, verified bool, err error) {
 	var domain domainv1beta1.CustomDomain
 	err = r.Get(ctx, types.NamespacedName{Name: reg.Spec.DomainName}, &domain)
@@ -331,15 +513,19 @@ func (r *CustomDomainRegistrationReconciler) verifyDomainIfNeeded(ctx context.Co
 	}
 
 	token := r.VerificationTokenGenerator(*domain.Spec.VerificationKey, string(reg.Namespace))
-	dnsRecordName, err := verification.MakeDNSRecordName(domain.Name)
+	verificationRecords, err := r.DomainVerifier.MakeDNSRecords(reg, token)
 	if err != nil {
 		return nil, false, err
 	}
-	records := append(
-		domain.Status.LoadBalancer.DNSRecords,
-		domainv1beta1.CustomDomainDNSRecord{Name: dnsRecordName, Type: "TXT", Value: token},
-	)
-	reg.Status.DNSRecords = records
+	if err := r.publishVerificationRecords(ctx, reg, &domain, verificationRecords); err != nil {
+		return nil, false, fmt.Errorf("cannot publish verification DNS records: %w", err)
+	}
+
+	var records []domainv1beta1.CustomDomainDNSRecord
+	if loadBalancer := expectedLoadBalancer(&domain); loadBalancer != nil {
+		records = append(records, loadBalancer.DNSRecords...)
+	}
+	reg.Status.DNSRecords = append(records, verificationRecords...)
 
 	currentVerified := false
 	for _, cond := range reg.Status.Conditions {
@@ -349,32 +535,87 @@ func (r *CustomDomainRegistrationReconciler) verifyDomainIfNeeded(ctx context.Co
 		}
 	}
 
+	lastSuccessTime := reg.Status.LastSuccessfulVerificationTime
+	if lastSuccessTime == nil && currentVerified {
+		lastSuccessTime = reg.Status.LastVerificationTime
+	}
+
 	now := r.Now()
 	now = metav1.Unix(now.Unix(), 0) // truncate to seconds
-	if reg.Spec.VerifyAt == nil ||
-		(reg.Status.LastVerificationTime != nil && reg.Status.LastVerificationTime.After(reg.Spec.VerifyAt.Time)) {
+	var verifyTime time.Time
+	periodic := false
+	if reg.Spec.VerifyAt != nil &&
+		(reg.Status.LastVerificationTime == nil || !reg.Status.LastVerificationTime.After(reg.Spec.VerifyAt.Time)) {
+		verifyTime = reg.Spec.VerifyAt.Time
+		if reg.Status.LastVerificationTime != nil &&
+			verifyTime.Before(reg.Status.LastVerificationTime.Add(VerificationCooldown)) {
+			// Too quick, apply cooldown period
+			verifyTime = reg.Status.LastVerificationTime.Add(VerificationCooldown)
+		}
+	} else if currentVerified && ReverificationInterval > 0 && reg.Status.LastVerificationTime != nil {
+		periodic = true
+		verifyTime = reg.Status.LastVerificationTime.Add(ReverificationInterval)
+		if lastSuccessTime != nil && lastSuccessTime.Before(reg.Status.LastVerificationTime) {
+			// Failing re-verification, check again when grace period expires
+			graceEnd := lastSuccessTime.Add(VerificationGracePeriod)
+			if graceEnd.Before(verifyTime) {
+				verifyTime = graceEnd
+			}
+		}
+	} else {
 		return nil, currentVerified, nil
 	}
-	verifyTime := reg.Spec.VerifyAt.Time
-	if reg.Status.LastVerificationTime != nil &&
-		verifyTime.Before(reg.Status.LastVerificationTime.Add(VerificationCooldown)) {
-		// Too quick, apply cooldown period
-		verifyTime = reg.Status.LastVerificationTime.Add(VerificationCooldown)
-	}
 	if !now.After(verifyTime) {
 		return &verifyTime, currentVerified, nil
 	}
 
-	err = func() error {
-		verifyCtx, cancel := context.WithTimeout(ctx, VerificationTimeout)
-		defer cancel()
-		return r.DomainVerifier(verifyCtx, domain.Name, token)
-	}()
+	tokens := []string{token}
+	if domain.Spec.PreviousVerificationKey != nil &&
+		(domain.Spec.PreviousVerificationKeyExpireAt == nil || now.Before(domain.Spec.PreviousVerificationKeyExpireAt)) {
+		// Accept token of previous key during key rotation
+		tokens = append(tokens, r.VerificationTokenGenerator(*domain.Spec.PreviousVerificationKey, string(reg.Namespace)))
+	}
 
-	// TODO(domain): re-verify periodically
+	var results []domainv1beta1.CustomDomainVerificationResult
+	for i, token := range tokens {
+		tokenResults, tokenErr := func() ([]domainv1beta1.CustomDomainVerificationResult, error) {
+			verifyCtx, cancel := context.WithTimeout(ctx, VerificationTimeout)
+			defer cancel()
+			return r.DomainVerifier.VerifyDomain(verifyCtx, reg, token)
+		}()
+		// Report results of the passing token, or of the current key if
+		// none passes.
+		if i == 0 || tokenErr == nil {
+			results, err = tokenResults, tokenErr
+		}
+		if tokenErr == nil {
+			break
+		}
+	}
 
 	reg.Status.LastVerificationTime = &now
-	return nil, err == nil, err
+	reg.Status.VerificationResults = results
+	if err == nil {
+		reg.Status.LastSuccessfulVerificationTime = &now
+		if ReverificationInterval > 0 {
+			nextTime := now.Add(ReverificationInterval)
+			return &nextTime, true, nil
+		}
+		return nil, true, nil
+	}
+
+	if periodic && lastSuccessTime != nil {
+		// Keep the domain verified until grace period expires
+		graceEnd := lastSuccessTime.Add(VerificationGracePeriod)
+		if now.Time.Before(graceEnd) {
+			nextTime := now.Add(ReverificationInterval)
+			if graceEnd.Before(nextTime) {
+				nextTime = graceEnd
+			}
+			return &nextTime, true, err
+		}
+	}
+	return nil, false, err
 }
 
 func (r *CustomDomainRegistrationReconciler) checkAcceptance(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (accepted bool, err error) {
@@ -388,6 +629,52 @@ func (r *CustomDomainRegistrationReconciler) checkAcceptance(ctx context.Context
 	return accepted, nil
 }
 
+// checkDNSConfigured checks whether DNS records of the domain point to its
+// load balancer, and returns the time of next check. Configured DNS records
+// are re-checked periodically, since they may be changed by the user or the
+// load balancer may be migrated.
+func (r *CustomDomainRegistrationReconciler) checkDNSConfigured(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (configured bool, nextCheckTime time.Time, err error) {
+	now := r.Now()
+	nextCheckTime = now.Add(DNSCheckInterval)
+
+	cond := condition.Lookup(reg.Status.Conditions, string(domainv1beta1.RegistrationDNSConfigured))
+	if cond != nil && cond.Status == metav1.ConditionTrue && reg.Status.LastDNSCheckTime != nil {
+		if checkTime := reg.Status.LastDNSCheckTime.Add(DNSCheckInterval); now.Time.Before(checkTime) {
+			return true, checkTime, nil
+		}
+	}
+
+	var domain domainv1beta1.CustomDomain
+	err = r.Get(ctx, types.NamespacedName{Name: reg.Spec.DomainName}, &domain)
+	if err != nil {
+		return false, nextCheckTime, err
+	}
+
+	loadBalancer := expectedLoadBalancer(&domain)
+	if loadBalancer == nil || len(loadBalancer.DNSRecords) == 0 {
+		return false, nextCheckTime, nil
+	}
+
+	err = func() error {
+		checkCtx, cancel := context.WithTimeout(ctx, VerificationTimeout)
+		defer cancel()
+		return r.DomainVerifier.CheckDNSRecords(checkCtx, domain.Name, loadBalancer.DNSRecords)
+	}()
+	reg.Status.LastDNSCheckTime = &now
+	return err == nil, nextCheckTime, err
+}
+
+// expectedLoadBalancer returns the load balancer that DNS records of the
+// domain should point to, which are also the records published into managed
+// zones.
+func expectedLoadBalancer(domain *domainv1beta1.CustomDomain) *domainv1beta1.CustomDomainStatusLoadBalancer {
+	if domain.Status.TargetLoadBalancer != nil && len(domain.Status.TargetLoadBalancer.DNSRecords) > 0 {
+		// Domain is migrating, DNS records should point to the target
+		return domain.Status.TargetLoadBalancer
+	}
+	return domain.Status.LoadBalancer
+}
+
 func (r *CustomDomainRegistrationReconciler) updateIngress(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (bool, error) {
 	ingress, err := r.IngressProvider.MakeIngress(reg)
 	if err != nil {
@@ -438,3 +725,94 @@ func (r *CustomDomainRegistrationReconciler) deleteIngress(ctx context.Context,
 
 	return true, nil
 }
+
+type certExpiry struct {
+	condition   api.Condition
+	requeueTime *time.Time
+}
+
+// checkCertificateExpiry updates status of the TLS certificate in use, and
+// reports whether the certificate is expiring within the alert thresholds.
+func (r *CustomDomainRegistrationReconciler) checkCertificateExpiry(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (*certExpiry, error) {
+	if reg.Status.CertSecretName == nil {
+		reg.Status.Certificate = nil
+		return nil, nil
+	}
+
+	var secret corev1.Secret
+	if err := r.Get(ctx, types.NamespacedName{Namespace: reg.Namespace, Name: *reg.Status.CertSecretName}, &secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			reg.Status.Certificate = nil
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	chain, err := tls.ParseCertificateChain(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse certificate: %w", err)
+	}
+	leaf := chain[0]
+	reg.Status.Certificate = &domainv1beta1.CustomDomainRegistrationStatusCertificate{
+		Issuer:       leaf.Issuer.String(),
+		SerialNumber: leaf.SerialNumber.Text(16),
+		NotBefore:    metav1.NewTime(leaf.NotBefore),
+		NotAfter:     metav1.NewTime(leaf.NotAfter),
+	}
+
+	if len(r.CertExpiryThresholds) == 0 {
+		return nil, nil
+	}
+
+	// thresholds are in descending order
+	now := r.Now().Time
+	remaining := leaf.NotAfter.Sub(now)
+	var threshold *time.Duration
+	var requeueTime *time.Time
+	for i, t := range r.CertExpiryThresholds {
+		if remaining <= t {
+			threshold = &r.CertExpiryThresholds[i]
+			continue
+		}
+		next := leaf.NotAfter.Add(-t)
+		requeueTime = &next
+		break
+	}
+	if requeueTime == nil && remaining > 0 {
+		requeueTime = &leaf.NotAfter
+	}
+
+	expiry := &certExpiry{requeueTime: requeueTime}
+	switch {
+	case remaining <= 0:
+		expiry.condition = api.Condition{
+			Type:    string(domainv1beta1.RegistrationCertExpiringSoon),
+			Status:  metav1.ConditionTrue,
+			Reason:  "Expired",
+			Message: "certificate has expired",
+		}
+	case threshold != nil:
+		expiry.condition = api.Condition{
+			Type:    string(domainv1beta1.RegistrationCertExpiringSoon),
+			Status:  metav1.ConditionTrue,
+			Reason:  "ExpiringSoon",
+			Message: fmt.Sprintf("certificate expires within %s", *threshold),
+		}
+	default:
+		expiry.condition = api.Condition{
+			Type:    string(domainv1beta1.RegistrationCertExpiringSoon),
+			Status:  metav1.ConditionFalse,
+			Reason:  "Valid",
+			Message: fmt.Sprintf("certificate expires at %s", leaf.NotAfter.UTC().Format(time.RFC3339)),
+		}
+	}
+
+	// alert once on crossing each threshold
+	old := condition.Lookup(reg.Status.Conditions, string(domainv1beta1.RegistrationCertExpiringSoon))
+	if expiry.condition.Status == metav1.ConditionTrue && (old == nil || old.Message != expiry.condition.Message) {
+		r.Recorder.Eventf(reg, corev1.EventTypeWarning, "CertExpiringSoon",
+			"%s: %s", *reg.Status.CertSecretName, expiry.condition.Message)
+	}
+
+	return expiry, nil
+}
@@ -0,0 +1,56 @@
+package controllers_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+)
+
+var _ = Describe("Domain DNS configuration", func() {
+	const namespace = "dnscheck"
+	const domain = "dnscheck.test"
+
+	It("Should withhold certificate until DNS is configured", func() {
+		createRegistration(namespace, domain)
+		records := waitDNSRecords(namespace, domain)
+		var loadBalancerRecords, verificationRecords []domainv1beta1.CustomDomainDNSRecord
+		for _, record := range records {
+			if record.Type == "TXT" {
+				verificationRecords = append(verificationRecords, record)
+			} else {
+				loadBalancerRecords = append(loadBalancerRecords, record)
+			}
+		}
+		Expect(loadBalancerRecords).NotTo(BeEmpty())
+		Expect(verificationRecords).NotTo(BeEmpty())
+
+		setDNSRecords(verificationRecords)
+		requestVerification(namespace, domain)
+		accepted := registrationCondition(namespace, domain, domainv1beta1.RegistrationAccepted)
+		Eventually(accepted, testTimeout, testInterval).Should(Equal(metav1.ConditionTrue))
+
+		dnsConfigured := registrationCondition(namespace, domain, domainv1beta1.RegistrationDNSConfigured)
+		certReady := registrationCondition(namespace, domain, domainv1beta1.RegistrationCertReady)
+		By("withholding certificate without DNS records")
+		Eventually(dnsConfigured, testTimeout, testInterval).Should(Equal(metav1.ConditionFalse))
+		Consistently(func() []metav1.ConditionStatus {
+			return []metav1.ConditionStatus{dnsConfigured(), certReady()}
+		}, "2s", testInterval).Should(Equal([]metav1.ConditionStatus{metav1.ConditionFalse, metav1.ConditionFalse}))
+		Expect(getRegistration(namespace, domain).Status.CertSecretName).To(BeNil())
+
+		By("provisioning certificate after DNS is configured")
+		setDNSRecords(loadBalancerRecords)
+		Eventually(dnsConfigured, testTimeout, testInterval).Should(Equal(metav1.ConditionTrue))
+		Eventually(certReady, testTimeout, testInterval).Should(Equal(metav1.ConditionTrue))
+
+		By("re-checking configured DNS records")
+		for _, record := range loadBalancerRecords {
+			domainChecker.SetRecords(record.Name)
+		}
+		Eventually(dnsConfigured, testTimeout, testInterval).Should(Equal(metav1.ConditionFalse))
+
+		deleteRegistration(namespace, domain)
+	})
+})
@@ -0,0 +1,133 @@
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/util/condition"
+)
+
+const testTimeout = time.Second * 10
+const testInterval = time.Millisecond * 100
+
+func createRegistration(namespace, domain string) {
+	r := &domainv1beta1.CustomDomainRegistration{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      domain,
+		},
+		Spec: domainv1beta1.CustomDomainRegistrationSpec{
+			DomainName: domain,
+			DomainConfig: domainv1beta1.CustomDomainConfig{
+				BackendServiceName: "app",
+				BackendServicePort: 80,
+			},
+		},
+	}
+	Expect(k8sClient.Create(context.Background(), r)).Should(Succeed())
+}
+
+func getRegistration(namespace, domain string) *domainv1beta1.CustomDomainRegistration {
+	reg := &domainv1beta1.CustomDomainRegistration{}
+	n := types.NamespacedName{Namespace: namespace, Name: domain}
+	Expect(k8sClient.Get(context.Background(), n, reg)).To(Succeed())
+	return reg
+}
+
+func getDomain(domain string) *domainv1beta1.CustomDomain {
+	d := &domainv1beta1.CustomDomain{}
+	Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: domain}, d)).To(Succeed())
+	return d
+}
+
+func registrationCondition(namespace, domain string, t domainv1beta1.CustomDomainRegistrationConditionType) func() metav1.ConditionStatus {
+	return func() metav1.ConditionStatus {
+		reg := getRegistration(namespace, domain)
+		cond := condition.Lookup(reg.Status.Conditions, string(t))
+		if cond == nil {
+			return metav1.ConditionUnknown
+		}
+		return cond.Status
+	}
+}
+
+// configureDNS sets up DNS records of the registration in the fake domain
+// checker, and returns the records.
+func configureDNS(namespace, domain string) []domainv1beta1.CustomDomainDNSRecord {
+	records := waitDNSRecords(namespace, domain)
+	setDNSRecords(records)
+	return records
+}
+
+// waitDNSRecords waits for load balancer and verification DNS records of the
+// registration to be populated.
+func waitDNSRecords(namespace, domain string) []domainv1beta1.CustomDomainDNSRecord {
+	var records []domainv1beta1.CustomDomainDNSRecord
+	Eventually(func() error {
+		records = getRegistration(namespace, domain).Status.DNSRecords
+		if len(records) < 2 {
+			return fmt.Errorf("unexpected DNS record count: %#v", records)
+		}
+		return nil
+	}, testTimeout, testInterval).Should(Succeed())
+	return records
+}
+
+// setDNSRecords sets up the DNS records in the fake domain checker.
+func setDNSRecords(records []domainv1beta1.CustomDomainDNSRecord) {
+	values := map[string][]string{}
+	for _, record := range records {
+		values[record.Name] = append(values[record.Name], record.Value)
+	}
+	for name, v := range values {
+		domainChecker.SetRecords(name, v...)
+	}
+}
+
+// requestVerification requests the registration to be verified, and waits
+// for the verification to be performed.
+func requestVerification(namespace, domain string) {
+	ctx := context.Background()
+	var verifyAt metav1.Time
+	Eventually(func() error {
+		reg := getRegistration(namespace, domain)
+		verifyAt = metav1.Unix(metav1.Now().Unix(), 0)
+		if reg.Status.LastVerificationTime != nil &&
+			!verifyAt.After(reg.Status.LastVerificationTime.Time) {
+			verifyAt = metav1.Time{Time: reg.Status.LastVerificationTime.Add(1 + time.Second)}
+		}
+		reg.Spec.VerifyAt = &verifyAt
+		return k8sClient.Update(ctx, reg)
+	}, testTimeout, testInterval).Should(Succeed())
+
+	Eventually(func() error {
+		reg := getRegistration(namespace, domain)
+		if reg.Status.LastVerificationTime == nil ||
+			!reg.Status.LastVerificationTime.After(verifyAt.Time) {
+			return fmt.Errorf("verification not yet performed: %#v", reg.Status)
+		}
+		return nil
+	}, testTimeout, testInterval).Should(Succeed())
+}
+
+// deleteRegistration deletes the registration, and waits for the domain to
+// be cleaned up.
+func deleteRegistration(namespace, domain string) {
+	ctx := context.Background()
+	Expect(k8sClient.Delete(ctx, getRegistration(namespace, domain))).To(Succeed())
+	Eventually(func() bool {
+		err := k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: domain}, &domainv1beta1.CustomDomainRegistration{})
+		return apierrors.IsNotFound(err)
+	}, testTimeout, testInterval).Should(BeTrue())
+	Eventually(func() bool {
+		err := k8sClient.Get(ctx, types.NamespacedName{Name: domain}, &domainv1beta1.CustomDomain{})
+		return apierrors.IsNotFound(err)
+	}, testTimeout, testInterval).Should(BeTrue())
+}
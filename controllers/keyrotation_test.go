@@ -0,0 +1,108 @@
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/controllers"
+	internaltest "github.com/skygeario/k8s-controller/internal/test"
+	"github.com/skygeario/k8s-controller/pkg/domain/verification"
+)
+
+var _ = Describe("Verification key rotation", func() {
+	const namespace = "rotate"
+	const domain = "rotate.test"
+	const previousKey = "previous-verification-key"
+
+	It("Should accept previous key until it expires", func() {
+		ctx := context.Background()
+		previousToken := verification.GenerateDomainToken(previousKey, namespace)
+		currentToken := verification.GenerateDomainToken(internaltest.DomainKeyGenerator(), namespace)
+
+		createRegistration(namespace, domain)
+		Eventually(func() error {
+			d := getDomain(domain)
+			if d.Spec.VerificationKey == nil {
+				return fmt.Errorf("verification key not yet generated")
+			}
+			d.Spec.VerificationKey = pointer.StringPtr(previousKey)
+			return k8sClient.Update(ctx, d)
+		}, testTimeout, testInterval).Should(Succeed())
+
+		hasToken := func(records []domainv1beta1.CustomDomainDNSRecord, token string) bool {
+			for _, record := range records {
+				if record.Type == "TXT" && record.Value == token {
+					return true
+				}
+			}
+			return false
+		}
+		var records []domainv1beta1.CustomDomainDNSRecord
+		Eventually(func() bool {
+			records = getRegistration(namespace, domain).Status.DNSRecords
+			return hasToken(records, previousToken)
+		}, testTimeout, testInterval).Should(BeTrue())
+		setDNSRecords(records)
+		requestVerification(namespace, domain)
+		verified := registrationCondition(namespace, domain, domainv1beta1.RegistrationVerified)
+		Expect(verified()).To(Equal(metav1.ConditionTrue))
+
+		By("rotating verification key")
+		rotateAt := metav1.Unix(metav1.Now().Unix(), 0)
+		Eventually(func() error {
+			d := getDomain(domain)
+			d.Spec.RotateVerificationKeyAt = &rotateAt
+			return k8sClient.Update(ctx, d)
+		}, testTimeout, testInterval).Should(Succeed())
+		Eventually(func() error {
+			d := getDomain(domain)
+			if d.Spec.RotateVerificationKeyAt != nil {
+				return fmt.Errorf("verification key not yet rotated")
+			}
+			if d.Spec.VerificationKey == nil || *d.Spec.VerificationKey != internaltest.DomainKeyGenerator() {
+				return fmt.Errorf("unexpected verification key: %v", d.Spec.VerificationKey)
+			}
+			if d.Spec.PreviousVerificationKey == nil || *d.Spec.PreviousVerificationKey != previousKey {
+				return fmt.Errorf("unexpected previous verification key: %v", d.Spec.PreviousVerificationKey)
+			}
+			expireAt := d.Spec.PreviousVerificationKeyExpireAt
+			if expireAt == nil || expireAt.Time.Before(rotateAt.Add(controllers.VerificationKeyRotationPeriod)) {
+				return fmt.Errorf("unexpected previous verification key expiry: %v", expireAt)
+			}
+			return nil
+		}, testTimeout, testInterval).Should(Succeed())
+		Eventually(func() bool {
+			records := getRegistration(namespace, domain).Status.DNSRecords
+			return hasToken(records, currentToken) && !hasToken(records, previousToken)
+		}, testTimeout, testInterval).Should(BeTrue())
+
+		By("accepting previous key within rotation period")
+		requestVerification(namespace, domain)
+		reg := getRegistration(namespace, domain)
+		Expect(reg.Status.LastSuccessfulVerificationTime).NotTo(BeNil())
+		Expect(reg.Status.LastSuccessfulVerificationTime.Equal(reg.Status.LastVerificationTime)).To(BeTrue())
+
+		By("rejecting previous key after rotation period")
+		Eventually(func() *string {
+			return getDomain(domain).Spec.PreviousVerificationKey
+		}, 2*testTimeout, testInterval).Should(BeNil())
+		requestVerification(namespace, domain)
+		reg = getRegistration(namespace, domain)
+		Expect(reg.Status.LastVerificationTime.After(reg.Status.LastSuccessfulVerificationTime.Time)).To(BeTrue())
+
+		By("accepting current key")
+		setDNSRecords(getRegistration(namespace, domain).Status.DNSRecords)
+		requestVerification(namespace, domain)
+		reg = getRegistration(namespace, domain)
+		Expect(reg.Status.LastSuccessfulVerificationTime.Equal(reg.Status.LastVerificationTime)).To(BeTrue())
+		Expect(verified()).To(Equal(metav1.ConditionTrue))
+
+		deleteRegistration(namespace, domain)
+	})
+})
@@ -0,0 +1,165 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/skygeario/k8s-controller/api"
+	domain "github.com/skygeario/k8s-controller/api"
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/util/condition"
+	"github.com/skygeario/k8s-controller/pkg/util/finalizer"
+)
+
+type LoadBalancerClassValidator interface {
+	ValidateClass(class *domainv1beta1.LoadBalancerClass) error
+}
+
+// LoadBalancerClassReconciler reconciles a LoadBalancerClass object
+type LoadBalancerClassReconciler struct {
+	client.Client
+	Log       logr.Logger
+	Scheme    *runtime.Scheme
+	Validator LoadBalancerClassValidator
+}
+
+// +kubebuilder:rbac:groups=domain.skygear.io,resources=loadbalancerclasses,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=domain.skygear.io,resources=loadbalancerclasses/status,verbs=get;update;patch
+
+func (r *LoadBalancerClassReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	ctx := context.Background()
+	_ = r.Log.WithValues("loadbalancerclass", req.NamespacedName)
+
+	var class domainv1beta1.LoadBalancerClass
+	if err := r.Get(ctx, req.NamespacedName, &class); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	var conditions []api.Condition
+	doFinalize := false
+	if class.DeletionTimestamp == nil {
+		finalizerAdded, err := finalizer.Ensure(r, ctx, &class, domain.DomainFinalizer)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if finalizerAdded {
+			return ctrl.Result{Requeue: true}, nil
+		}
+
+		if err := r.Validator.ValidateClass(&class); err != nil {
+			conditions = append(conditions, api.Condition{
+				Type:    string(domainv1beta1.LoadBalancerClassReady),
+				Status:  metav1.ConditionFalse,
+				Message: err.Error(),
+			})
+		} else {
+			conditions = append(conditions, api.Condition{
+				Type:   string(domainv1beta1.LoadBalancerClassReady),
+				Status: metav1.ConditionTrue,
+			})
+		}
+	} else {
+		// Class is kept until no domains use it, so that their load
+		// balancers can be released.
+		inUse, err := r.isInUse(ctx, &class)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		doFinalize = !inUse
+		conditions = append(conditions, api.Condition{
+			Type:    string(domainv1beta1.LoadBalancerClassReady),
+			Status:  metav1.ConditionFalse,
+			Message: "load-balancer class is being deleted",
+		})
+	}
+
+	condition.MergeFrom(conditions, class.Status.Conditions)
+	class.Status.Conditions = conditions
+	if err := r.Status().Update(ctx, &class); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if doFinalize {
+		err := finalizer.Remove(r, ctx, &class, domain.DomainFinalizer)
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func (r *LoadBalancerClassReconciler) isInUse(ctx context.Context, class *domainv1beta1.LoadBalancerClass) (bool, error) {
+	var domains domainv1beta1.CustomDomainList
+	if err := r.List(ctx, &domains); err != nil {
+		return false, err
+	}
+	for _, d := range domains.Items {
+		for _, name := range loadBalancerProviderNames(&d) {
+			if name == class.Name {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// loadBalancerProviderNames returns names of load balancer providers used by
+// the domain.
+func loadBalancerProviderNames(d *domainv1beta1.CustomDomain) []string {
+	var names []string
+	for _, name := range []*string{d.Spec.LoadBalancerProvider, d.Spec.TargetLoadBalancerProvider} {
+		if name != nil {
+			names = append(names, *name)
+		}
+	}
+	for _, lb := range []*domainv1beta1.CustomDomainStatusLoadBalancer{d.Status.LoadBalancer, d.Status.TargetLoadBalancer} {
+		if lb != nil && lb.Provider != "" {
+			names = append(names, lb.Provider)
+		}
+	}
+	return names
+}
+
+func (r *LoadBalancerClassReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&domainv1beta1.LoadBalancerClass{}).
+		Watches(
+			&source.Kind{Type: &domainv1beta1.CustomDomain{}},
+			&handler.EnqueueRequestsFromMapFunc{
+				ToRequests: handler.ToRequestsFunc(func(o handler.MapObject) []ctrl.Request {
+					d, ok := o.Object.(*domainv1beta1.CustomDomain)
+					if !ok {
+						return nil
+					}
+					var reqs []ctrl.Request
+					for _, name := range loadBalancerProviderNames(d) {
+						reqs = append(reqs, ctrl.Request{NamespacedName: types.NamespacedName{Name: name}})
+					}
+					return reqs
+				}),
+			},
+		).
+		Complete(r)
+}
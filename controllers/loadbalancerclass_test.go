@@ -0,0 +1,67 @@
+package controllers_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+)
+
+var _ = Describe("Load balancer class", func() {
+	const namespace = "lb-class"
+	const domain = "lb-class.test"
+	const className = "class-test"
+
+	It("Should keep class in use by domains until released", func() {
+		ctx := context.Background()
+		class := &domainv1beta1.LoadBalancerClass{
+			ObjectMeta: metav1.ObjectMeta{Name: className},
+			Spec:       domainv1beta1.LoadBalancerClassSpec{Type: "static-ip"},
+		}
+		Expect(k8sClient.Create(ctx, class)).Should(Succeed())
+		getClass := func() (*domainv1beta1.LoadBalancerClass, error) {
+			c := &domainv1beta1.LoadBalancerClass{}
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: className}, c)
+			return c, err
+		}
+		Eventually(func() []string {
+			c, err := getClass()
+			Expect(err).ToNot(HaveOccurred())
+			return c.Finalizers
+		}, testTimeout, testInterval).ShouldNot(BeEmpty())
+
+		createRegistration(namespace, domain)
+		configureDNS(namespace, domain)
+		requestVerification(namespace, domain)
+		Eventually(func() error {
+			d := getDomain(domain)
+			d.Spec.TargetLoadBalancerProvider = pointer.StringPtr(className)
+			return k8sClient.Update(ctx, d)
+		}, testTimeout, testInterval).Should(Succeed())
+		Eventually(func() *domainv1beta1.CustomDomainStatusLoadBalancer {
+			return getDomain(domain).Status.TargetLoadBalancer
+		}, testTimeout, testInterval).ShouldNot(BeNil())
+
+		By("deleting class in use")
+		c, err := getClass()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(k8sClient.Delete(ctx, c)).Should(Succeed())
+		Consistently(func() error {
+			_, err := getClass()
+			return err
+		}, "2s", testInterval).Should(Succeed())
+
+		By("releasing domain")
+		deleteRegistration(namespace, domain)
+		Eventually(func() bool {
+			_, err := getClass()
+			return apierrors.IsNotFound(err)
+		}, testTimeout, testInterval).Should(BeTrue())
+	})
+})
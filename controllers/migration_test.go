@@ -0,0 +1,119 @@
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/pointer"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+)
+
+var _ = Describe("Load balancer migration", func() {
+	const namespace = "migrate"
+	const domain = "migrate.test"
+
+	loadBalancerAddress := func(lb *domainv1beta1.CustomDomainStatusLoadBalancer) string {
+		if lb == nil || len(lb.DNSRecords) == 0 {
+			return ""
+		}
+		return lb.DNSRecords[0].Value
+	}
+
+	It("Should cut over to target provider after DNS records point to it", func() {
+		ctx := context.Background()
+		createRegistration(namespace, domain)
+		configureDNS(namespace, domain)
+		requestVerification(namespace, domain)
+		Eventually(func() string {
+			return loadBalancerAddress(getDomain(domain).Status.LoadBalancer)
+		}, testTimeout, testInterval).Should(Equal("127.0.0.1"))
+
+		Eventually(func() error {
+			d := getDomain(domain)
+			d.Spec.TargetLoadBalancerProvider = pointer.StringPtr("other")
+			return k8sClient.Update(ctx, d)
+		}, testTimeout, testInterval).Should(Succeed())
+		Eventually(func() string {
+			return loadBalancerAddress(getDomain(domain).Status.TargetLoadBalancer)
+		}, testTimeout, testInterval).Should(Equal("127.0.0.2"))
+
+		By("waiting for DNS records pointing to target")
+		Consistently(func() error {
+			d := getDomain(domain)
+			if d.Spec.LoadBalancerProvider == nil || *d.Spec.LoadBalancerProvider != "test" {
+				return fmt.Errorf("unexpected load balancer provider: %v", d.Spec.LoadBalancerProvider)
+			}
+			if d.Status.LoadBalancerCutOverTime != nil {
+				return fmt.Errorf("unexpected cut-over: %v", d.Status.LoadBalancerCutOverTime)
+			}
+			return nil
+		}, "2s", testInterval).Should(Succeed())
+
+		By("cutting over to target")
+		domainChecker.SetRecords(domain, "127.0.0.2")
+		Eventually(func() error {
+			d := getDomain(domain)
+			if d.Spec.LoadBalancerProvider == nil || *d.Spec.LoadBalancerProvider != "other" {
+				return fmt.Errorf("unexpected load balancer provider: %v", d.Spec.LoadBalancerProvider)
+			}
+			if d.Spec.TargetLoadBalancerProvider != nil || d.Status.TargetLoadBalancer != nil {
+				return fmt.Errorf("migration not yet completed: %#v", d.Status)
+			}
+			if address := loadBalancerAddress(d.Status.LoadBalancer); address != "127.0.0.2" {
+				return fmt.Errorf("unexpected load balancer address: %s", address)
+			}
+			return nil
+		}, testTimeout, testInterval).Should(Succeed())
+
+		deleteRegistration(namespace, domain)
+	})
+
+	It("Should cut over to target region", func() {
+		const domain = "migrate-region.test"
+		ctx := context.Background()
+		loadBalancerRegion := func() string {
+			lb := getDomain(domain).Status.LoadBalancer
+			if lb == nil || len(lb.DNSRecords) == 0 {
+				return ""
+			}
+			return lb.Region
+		}
+		setRegion := func(region string) {
+			Eventually(func() error {
+				reg := getRegistration(namespace, domain)
+				reg.Spec.Region = pointer.StringPtr(region)
+				return k8sClient.Update(ctx, reg)
+			}, testTimeout, testInterval).Should(Succeed())
+		}
+
+		createRegistration(namespace, domain)
+		setRegion("region-a")
+		configureDNS(namespace, domain)
+		requestVerification(namespace, domain)
+		Eventually(loadBalancerRegion, testTimeout, testInterval).Should(Equal("region-a"))
+
+		By("changing region only")
+		setRegion("region-b")
+		Eventually(func() error {
+			d := getDomain(domain)
+			if d.Spec.Region == nil || *d.Spec.Region != "region-b" {
+				return fmt.Errorf("unexpected region: %v", d.Spec.Region)
+			}
+			if d.Spec.LoadBalancerProvider == nil || *d.Spec.LoadBalancerProvider != "test" {
+				return fmt.Errorf("unexpected load balancer provider: %v", d.Spec.LoadBalancerProvider)
+			}
+			if d.Status.TargetLoadBalancer != nil || d.Status.LoadBalancerCutOverTime != nil {
+				return fmt.Errorf("migration not yet completed: %#v", d.Status)
+			}
+			if region := loadBalancerRegion(); region != "region-b" {
+				return fmt.Errorf("unexpected load balancer region: %s", region)
+			}
+			return nil
+		}, testTimeout, testInterval).Should(Succeed())
+
+		deleteRegistration(namespace, domain)
+	})
+})
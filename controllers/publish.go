@@ -0,0 +1,48 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+)
+
+type DNSPublisher interface {
+	Manages(domain string) bool
+	AllowsApp(domain string, app string) bool
+	Publish(ctx context.Context, domain string, records []domainv1beta1.CustomDomainDNSRecord) error
+	Unpublish(ctx context.Context, domain string, records []domainv1beta1.CustomDomainDNSRecord) error
+}
+
+// managedDNSRecords returns records within zones managed by the publisher.
+func managedDNSRecords(publisher DNSPublisher, domain string, records []domainv1beta1.CustomDomainDNSRecord) []domainv1beta1.CustomDomainDNSRecord {
+	if publisher == nil {
+		return nil
+	}
+
+	var managed []domainv1beta1.CustomDomainDNSRecord
+	for _, record := range records {
+		name := record.Name
+		if name == "@" {
+			name = domain
+		}
+		if publisher.Manages(name) {
+			managed = append(managed, record)
+		}
+	}
+	return managed
+}
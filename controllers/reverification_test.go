@@ -0,0 +1,63 @@
+package controllers_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/util/condition"
+)
+
+var _ = Describe("Domain re-verification", func() {
+	const namespace = "reverify"
+	const domain = "reverify.test"
+
+	It("Should re-verify domain periodically with grace period", func() {
+		createRegistration(namespace, domain)
+		records := configureDNS(namespace, domain)
+		requestVerification(namespace, domain)
+		verified := registrationCondition(namespace, domain, domainv1beta1.RegistrationVerified)
+		Expect(verified()).To(Equal(metav1.ConditionTrue))
+
+		By("passing re-verification")
+		lastSuccess := getRegistration(namespace, domain).Status.LastSuccessfulVerificationTime
+		Expect(lastSuccess).NotTo(BeNil())
+		Eventually(func() error {
+			reg := getRegistration(namespace, domain)
+			if reg.Status.LastSuccessfulVerificationTime == nil ||
+				!reg.Status.LastSuccessfulVerificationTime.After(lastSuccess.Time) {
+				return fmt.Errorf("not yet re-verified: %#v", reg.Status)
+			}
+			if cond := condition.Lookup(reg.Status.Conditions, string(domainv1beta1.RegistrationVerified)); cond == nil || cond.Status != metav1.ConditionTrue {
+				return fmt.Errorf("unexpected verified condition: %#v", cond)
+			}
+			return nil
+		}, testTimeout, testInterval).Should(Succeed())
+
+		By("failing re-verification within grace period")
+		for _, record := range records {
+			if record.Type == "TXT" {
+				domainChecker.SetRecords(record.Name)
+			}
+		}
+		Eventually(func() error {
+			reg := getRegistration(namespace, domain)
+			if reg.Status.LastVerificationTime == nil || reg.Status.LastSuccessfulVerificationTime == nil ||
+				!reg.Status.LastVerificationTime.After(reg.Status.LastSuccessfulVerificationTime.Time) {
+				return fmt.Errorf("re-verification not yet failed: %#v", reg.Status)
+			}
+			if cond := condition.Lookup(reg.Status.Conditions, string(domainv1beta1.RegistrationVerified)); cond == nil || cond.Status != metav1.ConditionTrue {
+				return fmt.Errorf("unexpected verified condition: %#v", cond)
+			}
+			return nil
+		}, testTimeout, testInterval).Should(Succeed())
+
+		By("failing re-verification after grace period")
+		Eventually(verified, testTimeout, testInterval).Should(Equal(metav1.ConditionFalse))
+
+		deleteRegistration(namespace, domain)
+	})
+})
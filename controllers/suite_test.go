@@ -49,6 +49,7 @@ var testEnv *envtest.Environment
 var mgrStop chan struct{}
 
 var domainChecker = internaltest.NewDomainChecker()
+var tlsProvider *internaltest.TLSProvider
 
 func TestAPIs(t *testing.T) {
 	RegisterFailHandler(Fail)
@@ -63,6 +64,10 @@ var _ = BeforeSuite(func(done Done) {
 
 	controllers.VerificationCooldown = 3 * time.Second
 	controllers.PollInterval = 1 * time.Second
+	controllers.DNSCheckInterval = 1 * time.Second
+	controllers.ReverificationInterval = 3 * time.Second
+	controllers.VerificationGracePeriod = 6 * time.Second
+	controllers.VerificationKeyRotationPeriod = 8 * time.Second
 
 	By("bootstrapping test environment")
 	testEnv = &envtest.Environment{
@@ -81,7 +86,7 @@ var _ = BeforeSuite(func(done Done) {
 	mgr, err := ctrl.NewManager(cfg, ctrl.Options{Scheme: scheme.Scheme})
 	Expect(err).ToNot(HaveOccurred())
 
-	tlsProvider := internaltest.NewTLSProvider(mgr.GetClient())
+	tlsProvider = internaltest.NewTLSProvider(mgr.GetClient())
 	loadBalancer := internaltest.NewLoadBalancer()
 	ingressProvider, err := nginx.NewProvider()
 	Expect(err).ToNot(HaveOccurred())
@@ -92,9 +97,10 @@ var _ = BeforeSuite(func(done Done) {
 		Scheme:                     mgr.GetScheme(),
 		Now:                        metav1.Now,
 		VerificationTokenGenerator: verification.GenerateDomainToken,
-		DomainVerifier:             domainChecker.VerifyDomain,
+		DomainVerifier:             domainChecker,
 		TLSProvider:                tlsProvider,
 		IngressProvider:            ingressProvider,
+		Recorder:                   mgr.GetEventRecorderFor("customdomainregistration-controller"),
 	}).SetupWithManager(mgr)
 	Expect(err).ToNot(HaveOccurred())
 
@@ -104,7 +110,17 @@ var _ = BeforeSuite(func(done Done) {
 		Scheme:                   mgr.GetScheme(),
 		Now:                      metav1.Now,
 		LoadBalancer:             loadBalancer,
+		DNSChecker:               domainChecker,
 		VerificationKeyGenerator: internaltest.DomainKeyGenerator,
+		IngressProvider:          ingressProvider,
+	}).SetupWithManager(mgr)
+	Expect(err).ToNot(HaveOccurred())
+
+	err = (&controllers.LoadBalancerClassReconciler{
+		Client:    mgr.GetClient(),
+		Log:       ctrl.Log.WithName("controllers").WithName("LoadBalancerClass"),
+		Scheme:    mgr.GetScheme(),
+		Validator: loadBalancer,
 	}).SetupWithManager(mgr)
 	Expect(err).ToNot(HaveOccurred())
 
@@ -3,7 +3,11 @@ package controllers
 import "time"
 
 var (
-	VerificationCooldown time.Duration = 60 * time.Second
-	VerificationTimeout  time.Duration = 5 * time.Second
-	PollInterval         time.Duration = 10 * time.Second
+	VerificationCooldown          time.Duration = 60 * time.Second
+	VerificationTimeout           time.Duration = 5 * time.Second
+	PollInterval                  time.Duration = 10 * time.Second
+	DNSCheckInterval              time.Duration = 30 * time.Second
+	ReverificationInterval        time.Duration = 1 * time.Hour
+	VerificationGracePeriod       time.Duration = 24 * time.Hour
+	VerificationKeyRotationPeriod time.Duration = 7 * 24 * time.Hour
 )
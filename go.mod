@@ -4,9 +4,11 @@ go 1.13
 
 require (
 	github.com/go-logr/logr v0.1.0
-	github.com/jetstack/cert-manager v0.13.0
+	github.com/miekg/dns v1.1.27
 	github.com/onsi/ginkgo v1.10.1
 	github.com/onsi/gomega v1.7.0
+	github.com/prometheus/client_golang v1.0.0
+	golang.org/x/crypto v0.0.0-20191011191535-87dc89f01550
 	golang.org/x/net v0.0.0-20191004110552-13f9640d40b9
 	k8s.io/api v0.17.0
 	k8s.io/apimachinery v0.17.0
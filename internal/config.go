@@ -1,11 +1,31 @@
 package internal
 
 import (
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer/cdn"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer/cname"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer/dedicatedip"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer/plugin"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer/service"
 	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer/staticip"
+	"github.com/skygeario/k8s-controller/pkg/domain/publish/externaldns"
+	"github.com/skygeario/k8s-controller/pkg/domain/publish/rfc2136"
+	"github.com/skygeario/k8s-controller/pkg/domain/tls"
+	"github.com/skygeario/k8s-controller/pkg/domain/tls/acme"
 	"github.com/skygeario/k8s-controller/pkg/domain/tls/certmanager"
+	"github.com/skygeario/k8s-controller/pkg/domain/verification"
 )
 
 type Config struct {
-	StaticIP    *staticip.Config
-	CertManager *certmanager.Config
+	StaticIP            *staticip.Config
+	CNAME               *cname.Config
+	Service             *service.Config
+	DedicatedIP         *dedicatedip.Config
+	CDN                 *cdn.Config
+	LoadBalancerPlugins map[string]plugin.Config
+	CertManager         *certmanager.Config
+	ACME                *acme.Config
+	CertExpiry          *tls.ExpiryConfig
+	Verification        *verification.Config
+	RFC2136             *rfc2136.Config
+	ExternalDNS         *externaldns.Config
 }
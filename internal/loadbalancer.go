@@ -2,23 +2,49 @@ package internal
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 
 	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
 	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer/cdn"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer/cname"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer/dedicatedip"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer/plugin"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer/service"
 	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer/staticip"
 	"golang.org/x/net/publicsuffix"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
-	loadBalancerStaticIP string = "static-ip"
+	loadBalancerStaticIP    string = "static-ip"
+	loadBalancerCNAME       string = "cname"
+	loadBalancerService     string = "service"
+	loadBalancerDedicatedIP string = "dedicated-ip"
+	loadBalancerCDN         string = "cdn"
+	loadBalancerPlugin      string = "plugin"
 )
 
 type LoadBalancer struct {
-	StaticIP *staticip.Provider
+	StaticIP    *staticip.Provider
+	CNAME       *cname.Provider
+	Service     *service.Provider
+	DedicatedIP *dedicatedip.Provider
+	CDN         *cdn.Provider
+	Plugins     map[string]*plugin.Provider
+	// OnHealthChange is called when health of addresses of any static IP
+	// load-balancer class is changed.
+	OnHealthChange func()
+
+	client      client.Client
+	classesLock sync.Mutex
+	classes     map[string]*classProvider
+	started     bool
 }
 
-func NewLoadBalancer(config Config) (*LoadBalancer, error) {
+func NewLoadBalancer(client client.Client, config Config) (*LoadBalancer, error) {
 	var err error
 	var staticIP *staticip.Provider
 	if config.StaticIP != nil {
@@ -28,13 +54,64 @@ func NewLoadBalancer(config Config) (*LoadBalancer, error) {
 		}
 	}
 
+	var cnameProvider *cname.Provider
+	if config.CNAME != nil {
+		cnameProvider, err = cname.NewProvider(*config.CNAME)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create CNAME provider: %w", err)
+		}
+	}
+
+	var serviceProvider *service.Provider
+	if config.Service != nil {
+		serviceProvider, err = service.NewProvider(client, *config.Service)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create Service provider: %w", err)
+		}
+	}
+
+	var dedicatedIP *dedicatedip.Provider
+	if config.DedicatedIP != nil {
+		dedicatedIP, err = dedicatedip.NewProvider(client, *config.DedicatedIP)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create dedicated IP provider: %w", err)
+		}
+	}
+
+	var cdnProvider *cdn.Provider
+	if config.CDN != nil {
+		cdnProvider, err = cdn.NewProvider(*config.CDN)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create CDN provider: %w", err)
+		}
+	}
+
+	plugins := map[string]*plugin.Provider{}
+	for name, pluginConfig := range config.LoadBalancerPlugins {
+		switch name {
+		case loadBalancerStaticIP, loadBalancerCNAME, loadBalancerService, loadBalancerDedicatedIP, loadBalancerCDN:
+			return nil, fmt.Errorf("load-balancer plugin name '%s' is reserved", name)
+		}
+		plugins[name], err = plugin.NewProvider(name, pluginConfig)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create load-balancer plugin '%s': %w", name, err)
+		}
+	}
+
 	return &LoadBalancer{
-		StaticIP: staticIP,
+		StaticIP:    staticIP,
+		CNAME:       cnameProvider,
+		Service:     serviceProvider,
+		DedicatedIP: dedicatedIP,
+		CDN:         cdnProvider,
+		Plugins:     plugins,
+		client:      client,
+		classes:     map[string]*classProvider{},
 	}, nil
 }
 
 func (p *LoadBalancer) Provision(ctx context.Context, domain *domainv1beta1.CustomDomain) (string, *loadbalancer.ProvisionResult, error) {
-	providerType, provider, err := p.selectProvider(domain)
+	providerType, provider, err := p.selectProvider(ctx, domain)
 	if err != nil {
 		return "", nil, err
 	}
@@ -44,47 +121,95 @@ func (p *LoadBalancer) Provision(ctx context.Context, domain *domainv1beta1.Cust
 }
 
 func (p *LoadBalancer) Release(ctx context.Context, domain *domainv1beta1.CustomDomain) (bool, error) {
-	_, provider, err := p.selectProvider(domain)
-	if err != nil {
+	_, provider, err := p.selectProvider(ctx, domain)
+	if errors.Is(err, errClassNotFound) {
+		// Provider no longer exists, so there is nothing to release; classes
+		// in use are guarded by finalizer, so this happens only when it is
+		// removed forcibly.
+		return true, nil
+	} else if err != nil {
 		return false, err
 	}
 	return provider.Release(ctx, domain)
 }
 
-func (p *LoadBalancer) selectProvider(domain *domainv1beta1.CustomDomain) (string, loadbalancer.Provider, error) {
+func (p *LoadBalancer) selectProvider(ctx context.Context, domain *domainv1beta1.CustomDomain) (string, loadbalancer.Provider, error) {
+	rootDomain, err := publicsuffix.EffectiveTLDPlusOne(domain.Name)
+	if err != nil {
+		return "", nil, err
+	}
+	isApex := domain.Name == rootDomain
+
 	if domain.Spec.LoadBalancerProvider != nil {
 		t := *domain.Spec.LoadBalancerProvider
 		provider, err := p.lookupProvider(t)
-		if err != nil {
-			return "", nil, err
+		if err == nil {
+			return t, provider, nil
+		}
+
+		provider, classErr := p.lookupClass(ctx, t, isApex)
+		if classErr == nil {
+			return t, provider, nil
+		} else if classErr != errClassNotFound {
+			return "", nil, classErr
 		}
-		return t, provider, nil
+		return "", nil, fmt.Errorf("%s: %w", err, errClassNotFound)
 	}
 
-	rootDomain, err := publicsuffix.EffectiveTLDPlusOne(domain.Name)
+	name, provider, err := p.lookupDefaultClass(ctx, isApex)
 	if err != nil {
 		return "", nil, err
+	} else if provider != nil {
+		return name, provider, nil
 	}
 
-	if domain.Name == rootDomain {
+	if isApex {
 		// no CDN for root domain
 		if p.StaticIP != nil {
 			return loadBalancerStaticIP, p.StaticIP, nil
 		}
 	} else {
 		// allow CDN for sub-domains
+		if p.CDN != nil {
+			return loadBalancerCDN, p.CDN, nil
+		}
+		if p.CNAME != nil {
+			return loadBalancerCNAME, p.CNAME, nil
+		}
+	}
+
+	if p.Service != nil {
+		return loadBalancerService, p.Service, nil
 	}
 
 	return "", nil, fmt.Errorf("no available load-balancer provider for the domain")
 }
 
 func (p *LoadBalancer) lookupProvider(providerType string) (loadbalancer.Provider, error) {
-	providers := map[string]loadbalancer.Provider{
-		loadBalancerStaticIP: p.StaticIP,
-	}
-	for t, p := range providers {
-		if t == providerType {
-			return p, nil
+	switch providerType {
+	case loadBalancerStaticIP:
+		if p.StaticIP != nil {
+			return p.StaticIP, nil
+		}
+	case loadBalancerCNAME:
+		if p.CNAME != nil {
+			return p.CNAME, nil
+		}
+	case loadBalancerService:
+		if p.Service != nil {
+			return p.Service, nil
+		}
+	case loadBalancerDedicatedIP:
+		if p.DedicatedIP != nil {
+			return p.DedicatedIP, nil
+		}
+	case loadBalancerCDN:
+		if p.CDN != nil {
+			return p.CDN, nil
+		}
+	default:
+		if plugin, ok := p.Plugins[providerType]; ok {
+			return plugin, nil
 		}
 	}
 
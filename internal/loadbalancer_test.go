@@ -0,0 +1,203 @@
+package internal_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/internal"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer/cname"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer/staticip"
+)
+
+func newClass(name string, address string, apex, sub, isDefault bool) *domainv1beta1.LoadBalancerClass {
+	return &domainv1beta1.LoadBalancerClass{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Generation: 1},
+		Spec: domainv1beta1.LoadBalancerClassSpec{
+			Type:             "static-ip",
+			Parameters:       &runtime.RawExtension{Raw: []byte(fmt.Sprintf(`{"IPAddresses":["%s"]}`, address))},
+			AllowApexDomains: apex,
+			AllowSubdomains:  sub,
+			Default:          isDefault,
+		},
+	}
+}
+
+func newLoadBalancer(t *testing.T, classes ...runtime.Object) (*internal.LoadBalancer, client.Client) {
+	scheme := runtime.NewScheme()
+	_ = domainv1beta1.AddToScheme(scheme)
+	kubeClient := fake.NewFakeClientWithScheme(scheme, classes...)
+
+	lb, err := internal.NewLoadBalancer(kubeClient, internal.Config{
+		StaticIP: &staticip.Config{IPAddresses: []string{"192.0.2.1"}},
+		CNAME:    &cname.Config{Target: "lb.example.test"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return lb, kubeClient
+}
+
+func TestSelectProvider(t *testing.T) {
+	cases := []struct {
+		name     string
+		classes  []runtime.Object
+		domain   string
+		provider *string
+		expected string
+	}{
+		{"apex domain", nil, "my-app.test", nil, "static-ip"},
+		{"subdomain", nil, "www.my-app.test", nil, "cname"},
+		{"default class", []runtime.Object{
+			newClass("default-lb", "192.0.2.10", true, true, true),
+		}, "www.my-app.test", nil, "default-lb"},
+		{"default classes by name", []runtime.Object{
+			newClass("b-lb", "192.0.2.10", true, true, true),
+			newClass("a-lb", "192.0.2.11", true, true, true),
+		}, "my-app.test", nil, "a-lb"},
+		{"default class not serving apex", []runtime.Object{
+			newClass("sub-lb", "192.0.2.10", false, true, true),
+		}, "my-app.test", nil, "static-ip"},
+		{"default class with reserved name", []runtime.Object{
+			newClass("cname", "192.0.2.10", true, true, true),
+		}, "my-app.test", nil, "static-ip"},
+		{"configured provider", []runtime.Object{
+			newClass("default-lb", "192.0.2.10", true, true, true),
+		}, "www.my-app.test", pointer.StringPtr("cname"), "cname"},
+		{"class by name", []runtime.Object{
+			newClass("class-lb", "192.0.2.10", true, true, false),
+		}, "my-app.test", pointer.StringPtr("class-lb"), "class-lb"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			lb, _ := newLoadBalancer(t, c.classes...)
+			domain := &domainv1beta1.CustomDomain{
+				ObjectMeta: metav1.ObjectMeta{Name: c.domain},
+				Spec:       domainv1beta1.CustomDomainSpec{LoadBalancerProvider: c.provider},
+			}
+			providerType, _, err := lb.Provision(context.Background(), domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if providerType != c.expected {
+				t.Errorf("expected provider %s, got %s", c.expected, providerType)
+			}
+		})
+	}
+
+	lb, _ := newLoadBalancer(t, newClass("sub-lb", "192.0.2.10", false, true, false))
+	for _, provider := range []string{"unknown-lb", "sub-lb"} {
+		domain := &domainv1beta1.CustomDomain{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-app.test"},
+			Spec:       domainv1beta1.CustomDomainSpec{LoadBalancerProvider: pointer.StringPtr(provider)},
+		}
+		if _, _, err := lb.Provision(context.Background(), domain); err == nil {
+			t.Errorf("expected provider %s to be rejected", provider)
+		}
+	}
+}
+
+func TestClassUpdate(t *testing.T) {
+	class := newClass("class-lb", "192.0.2.10", true, true, false)
+	lb, kubeClient := newLoadBalancer(t, class)
+	ctx := context.Background()
+	domain := &domainv1beta1.CustomDomain{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-app.test"},
+		Spec:       domainv1beta1.CustomDomainSpec{LoadBalancerProvider: pointer.StringPtr("class-lb")},
+	}
+	address := func() string {
+		_, result, err := lb.Provision(ctx, domain)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result.DNSRecords[0].Value
+	}
+
+	if a := address(); a != "192.0.2.10" {
+		t.Errorf("unexpected address: %s", a)
+	}
+
+	class.Spec.Parameters.Raw = []byte(`{"IPAddresses":["192.0.2.20"]}`)
+	class.Generation++
+	if err := kubeClient.Update(ctx, class); err != nil {
+		t.Fatal(err)
+	}
+	if a := address(); a != "192.0.2.20" {
+		t.Errorf("expected updated class to be used, got %s", a)
+	}
+
+	if err := kubeClient.Delete(ctx, class); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := lb.Provision(ctx, domain); err == nil {
+		t.Errorf("expected deleted class to be rejected")
+	}
+}
+
+func TestReleaseDeletedClass(t *testing.T) {
+	lb, _ := newLoadBalancer(t)
+	domain := &domainv1beta1.CustomDomain{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-app.test"},
+		Spec:       domainv1beta1.CustomDomainSpec{LoadBalancerProvider: pointer.StringPtr("class-lb")},
+	}
+	ok, err := lb.Release(context.Background(), domain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Errorf("expected domain of deleted class to be released")
+	}
+}
+
+func TestClassHealthCheck(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	class := newClass("class-lb", "127.0.0.1", true, true, false)
+	class.Spec.Parameters.Raw = []byte(fmt.Sprintf(
+		`{"IPAddresses":["127.0.0.1"],"HealthCheck":{"Protocol":"tcp","Port":%d,"Interval":"50ms"}}`,
+		port,
+	))
+	lb, _ := newLoadBalancer(t, class)
+	changed := make(chan struct{}, 1)
+	lb.OnHealthChange = func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		_ = lb.Start(stop)
+	}()
+
+	domain := &domainv1beta1.CustomDomain{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-app.test"},
+		Spec:       domainv1beta1.CustomDomainSpec{LoadBalancerProvider: pointer.StringPtr("class-lb")},
+	}
+	if _, _, err := lb.Provision(context.Background(), domain); err != nil {
+		t.Fatal(err)
+	}
+
+	listener.Close()
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Error("health checker of load-balancer class is not started")
+	}
+}
@@ -0,0 +1,231 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer/cdn"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer/cname"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer/dedicatedip"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer/plugin"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer/service"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer/staticip"
+)
+
+var errClassNotFound = errors.New("load-balancer class not found")
+
+// classProvider is a provider created from a LoadBalancerClass, re-created
+// when the class is changed.
+type classProvider struct {
+	generation    int64
+	provider      loadbalancer.Provider
+	healthChecker *staticip.HealthChecker
+	stop          chan struct{}
+}
+
+func (c *classProvider) startHealthChecker() {
+	if c.healthChecker == nil || c.stop != nil {
+		return
+	}
+	c.stop = make(chan struct{})
+	go func(stop <-chan struct{}) {
+		_ = c.healthChecker.Start(stop)
+	}(c.stop)
+}
+
+func (c *classProvider) stopHealthChecker() {
+	if c.stop != nil {
+		close(c.stop)
+		c.stop = nil
+	}
+}
+
+var _ manager.Runnable = &LoadBalancer{}
+
+// Start runs health checkers of static IP load-balancer classes until stop
+// is closed.
+func (p *LoadBalancer) Start(stop <-chan struct{}) error {
+	p.classesLock.Lock()
+	p.started = true
+	for _, c := range p.classes {
+		c.startHealthChecker()
+	}
+	p.classesLock.Unlock()
+
+	<-stop
+
+	p.classesLock.Lock()
+	defer p.classesLock.Unlock()
+	p.started = false
+	for _, c := range p.classes {
+		c.stopHealthChecker()
+	}
+	return nil
+}
+
+// ValidateClass checks whether a provider can be created from the class.
+func (p *LoadBalancer) ValidateClass(class *domainv1beta1.LoadBalancerClass) error {
+	if _, err := p.lookupProvider(class.Name); err == nil {
+		return fmt.Errorf("load-balancer class name '%s' is reserved", class.Name)
+	}
+	if !class.Spec.AllowApexDomains && !class.Spec.AllowSubdomains {
+		return fmt.Errorf("load-balancer class cannot serve any domain")
+	}
+	_, err := p.makeClassProvider(class)
+	return err
+}
+
+func (p *LoadBalancer) lookupClass(ctx context.Context, name string, isApex bool) (loadbalancer.Provider, error) {
+	var class domainv1beta1.LoadBalancerClass
+	err := p.client.Get(ctx, types.NamespacedName{Name: name}, &class)
+	if apierrors.IsNotFound(err) {
+		p.evictClass(name)
+		return nil, errClassNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	if isApex && !class.Spec.AllowApexDomains {
+		return nil, fmt.Errorf("load-balancer class '%s' cannot serve apex domains", name)
+	} else if !isApex && !class.Spec.AllowSubdomains {
+		return nil, fmt.Errorf("load-balancer class '%s' cannot serve subdomains", name)
+	}
+	return p.classProvider(&class)
+}
+
+func (p *LoadBalancer) lookupDefaultClass(ctx context.Context, isApex bool) (string, loadbalancer.Provider, error) {
+	var list domainv1beta1.LoadBalancerClassList
+	if err := p.client.List(ctx, &list); err != nil {
+		return "", nil, err
+	}
+
+	// select by name if multiple default classes are eligible
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].Name < list.Items[j].Name
+	})
+	for _, class := range list.Items {
+		if !class.Spec.Default || class.DeletionTimestamp != nil {
+			continue
+		}
+		if (isApex && !class.Spec.AllowApexDomains) || (!isApex && !class.Spec.AllowSubdomains) {
+			continue
+		}
+		if _, err := p.lookupProvider(class.Name); err == nil {
+			// name is reserved by configured providers
+			continue
+		}
+
+		provider, err := p.classProvider(&class)
+		if err != nil {
+			return "", nil, err
+		}
+		return class.Name, provider, nil
+	}
+	return "", nil, nil
+}
+
+func (p *LoadBalancer) classProvider(class *domainv1beta1.LoadBalancerClass) (loadbalancer.Provider, error) {
+	p.classesLock.Lock()
+	defer p.classesLock.Unlock()
+
+	if c, ok := p.classes[class.Name]; ok {
+		if c.generation == class.Generation {
+			return c.provider, nil
+		}
+		c.stopHealthChecker()
+		delete(p.classes, class.Name)
+	}
+
+	provider, err := p.makeClassProvider(class)
+	if err != nil {
+		return nil, fmt.Errorf("invalid load-balancer class '%s': %w", class.Name, err)
+	}
+	c := &classProvider{generation: class.Generation, provider: provider}
+	if staticIP, ok := provider.(*staticip.Provider); ok && staticIP.HealthChecker != nil {
+		staticIP.HealthChecker.OnChange = p.notifyHealthChange
+		c.healthChecker = staticIP.HealthChecker
+		if p.started {
+			c.startHealthChecker()
+		}
+	}
+	p.classes[class.Name] = c
+	return provider, nil
+}
+
+func (p *LoadBalancer) evictClass(name string) {
+	p.classesLock.Lock()
+	defer p.classesLock.Unlock()
+
+	if c, ok := p.classes[name]; ok {
+		c.stopHealthChecker()
+		delete(p.classes, name)
+	}
+}
+
+func (p *LoadBalancer) notifyHealthChange() {
+	if p.OnHealthChange != nil {
+		p.OnHealthChange()
+	}
+}
+
+func (p *LoadBalancer) makeClassProvider(class *domainv1beta1.LoadBalancerClass) (loadbalancer.Provider, error) {
+	decode := func(config interface{}) error {
+		if class.Spec.Parameters == nil || len(class.Spec.Parameters.Raw) == 0 {
+			return nil
+		}
+		if err := json.Unmarshal(class.Spec.Parameters.Raw, config); err != nil {
+			return fmt.Errorf("invalid parameters: %w", err)
+		}
+		return nil
+	}
+
+	var provider loadbalancer.Provider
+	var err error
+	switch class.Spec.Type {
+	case loadBalancerStaticIP:
+		var config staticip.Config
+		if err = decode(&config); err == nil {
+			provider, err = staticip.NewProvider(config)
+		}
+	case loadBalancerCNAME:
+		var config cname.Config
+		if err = decode(&config); err == nil {
+			provider, err = cname.NewProvider(config)
+		}
+	case loadBalancerService:
+		var config service.Config
+		if err = decode(&config); err == nil {
+			provider, err = service.NewProvider(p.client, config)
+		}
+	case loadBalancerDedicatedIP:
+		var config dedicatedip.Config
+		if err = decode(&config); err == nil {
+			provider, err = dedicatedip.NewProvider(p.client, config)
+		}
+	case loadBalancerCDN:
+		var config cdn.Config
+		if err = decode(&config); err == nil {
+			provider, err = cdn.NewProvider(config)
+		}
+	case loadBalancerPlugin:
+		var config plugin.Config
+		if err = decode(&config); err == nil {
+			provider, err = plugin.NewProvider(class.Name, config)
+		}
+	default:
+		return nil, fmt.Errorf("load-balancer type '%s' is unknown", class.Spec.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return provider, nil
+}
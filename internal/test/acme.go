@@ -0,0 +1,613 @@
+package test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/acme"
+)
+
+const (
+	acmeProblemMalformed     = "urn:ietf:params:acme:error:malformed"
+	acmeProblemBadNonce      = "urn:ietf:params:acme:error:badNonce"
+	acmeProblemUnauthorized  = "urn:ietf:params:acme:error:unauthorized"
+	acmeProblemOrderNotReady = "urn:ietf:params:acme:error:orderNotReady"
+	acmeProblemBadCSR        = "urn:ietf:params:acme:error:badCSR"
+)
+
+type acmeOrder struct {
+	AccountURL  string
+	Identifiers []string
+	AuthzIDs    []string
+	Cert        []byte
+}
+
+type acmeAuthz struct {
+	Domain      string
+	Status      string
+	ChallengeID string
+}
+
+type acmeChallenge struct {
+	AuthzID string
+	Token   string
+	Status  string
+	Error   string
+}
+
+type acmeRequest struct {
+	Key        *ecdsa.PublicKey
+	AccountURL string
+	Payload    []byte
+}
+
+type acmeProblem struct {
+	Status int
+	Type   string
+	Detail string
+}
+
+// ACMEServer is an in-process ACME (RFC 8555) server validating HTTP-01
+// challenges, similar to Pebble.
+type ACMEServer struct {
+	Now func() time.Time
+	// ChallengeAddr is the address HTTP-01 challenges are validated against,
+	// with Host header set to the domain.
+	ChallengeAddr string
+	// CertificateLifetime is the validity period of issued certificates.
+	CertificateLifetime time.Duration
+
+	server     *httptest.Server
+	lock       sync.Mutex
+	nextID     int
+	nonces     map[string]bool
+	accounts   map[string]*ecdsa.PublicKey
+	orders     map[string]*acmeOrder
+	authzs     map[string]*acmeAuthz
+	challenges map[string]*acmeChallenge
+	caKey      *ecdsa.PrivateKey
+	caCert     *x509.Certificate
+}
+
+func NewACMEServer() (*ACMEServer, error) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test ACME CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, err
+	}
+	caCert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &ACMEServer{
+		Now:                 time.Now,
+		CertificateLifetime: 90 * 24 * time.Hour,
+		nonces:              map[string]bool{},
+		accounts:            map[string]*ecdsa.PublicKey{},
+		orders:              map[string]*acmeOrder{},
+		authzs:              map[string]*acmeAuthz{},
+		challenges:          map[string]*acmeChallenge{},
+		caKey:               caKey,
+		caCert:              caCert,
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s, nil
+}
+
+func (s *ACMEServer) DirectoryURL() string {
+	return s.server.URL + "/directory"
+}
+
+func (s *ACMEServer) Close() {
+	s.server.Close()
+}
+
+// NumAccounts returns the number of registered accounts.
+func (s *ACMEServer) NumAccounts() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return len(s.accounts)
+}
+
+// NumOrders returns the number of created orders.
+func (s *ACMEServer) NumOrders() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return len(s.orders)
+}
+
+// CACertificate returns the certificate of the issuing CA.
+func (s *ACMEServer) CACertificate() *x509.Certificate {
+	return s.caCert
+}
+
+func (s *ACMEServer) serveHTTP(rw http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	rw.Header().Set("Replay-Nonce", s.newNonce())
+
+	switch r.URL.Path {
+	case "/directory":
+		s.writeJSON(rw, http.StatusOK, "", map[string]interface{}{
+			"newNonce":   s.url("/new-nonce"),
+			"newAccount": s.url("/new-account"),
+			"newOrder":   s.url("/new-order"),
+			"revokeCert": s.url("/revoke-cert"),
+			"keyChange":  s.url("/key-change"),
+		})
+		return
+	case "/new-nonce":
+		rw.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		rw.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	req, problem := s.verifyRequest(r)
+	if problem != nil {
+		s.writeProblem(rw, problem)
+		return
+	}
+	if r.URL.Path != "/new-account" && req.AccountURL == "" {
+		s.writeProblem(rw, &acmeProblem{http.StatusUnauthorized, acmeProblemUnauthorized, "account is required"})
+		return
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	id := ""
+	if len(parts) == 2 {
+		id = parts[1]
+	}
+	switch parts[0] {
+	case "new-account":
+		s.newAccount(rw, req)
+	case "new-order":
+		s.newOrder(rw, req)
+	case "order":
+		s.getOrder(rw, req, id, http.StatusOK)
+	case "authz":
+		s.getAuthz(rw, req, id)
+	case "challenge":
+		s.acceptChallenge(rw, req, id)
+	case "finalize":
+		s.finalizeOrder(rw, req, id)
+	case "cert":
+		s.getCert(rw, req, id)
+	default:
+		http.NotFound(rw, r)
+	}
+}
+
+func (s *ACMEServer) newAccount(rw http.ResponseWriter, req *acmeRequest) {
+	if req.AccountURL == "" {
+		s.writeProblem(rw, &acmeProblem{http.StatusBadRequest, acmeProblemMalformed, "JWK is required"})
+		return
+	}
+	status := http.StatusOK
+	if _, ok := s.accounts[req.AccountURL]; !ok {
+		s.accounts[req.AccountURL] = req.Key
+		status = http.StatusCreated
+	}
+	s.writeJSON(rw, status, req.AccountURL, map[string]interface{}{"status": acme.StatusValid})
+}
+
+func (s *ACMEServer) newOrder(rw http.ResponseWriter, req *acmeRequest) {
+	var payload struct {
+		Identifiers []struct {
+			Type  string `json:"type"`
+			Value string `json:"value"`
+		} `json:"identifiers"`
+	}
+	if err := json.Unmarshal(req.Payload, &payload); err != nil || len(payload.Identifiers) == 0 {
+		s.writeProblem(rw, &acmeProblem{http.StatusBadRequest, acmeProblemMalformed, "invalid order"})
+		return
+	}
+
+	order := &acmeOrder{AccountURL: req.AccountURL}
+	for _, ident := range payload.Identifiers {
+		if ident.Type != "dns" {
+			s.writeProblem(rw, &acmeProblem{http.StatusBadRequest, acmeProblemMalformed, "unsupported identifier"})
+			return
+		}
+		authzID := s.newID()
+		challengeID := s.newID()
+		token := make([]byte, 32)
+		if _, err := rand.Read(token); err != nil {
+			panic(err)
+		}
+		s.authzs[authzID] = &acmeAuthz{Domain: ident.Value, Status: acme.StatusPending, ChallengeID: challengeID}
+		s.challenges[challengeID] = &acmeChallenge{
+			AuthzID: authzID,
+			Token:   base64.RawURLEncoding.EncodeToString(token),
+			Status:  acme.StatusPending,
+		}
+		order.Identifiers = append(order.Identifiers, ident.Value)
+		order.AuthzIDs = append(order.AuthzIDs, authzID)
+	}
+
+	id := s.newID()
+	s.orders[id] = order
+	s.getOrder(rw, req, id, http.StatusCreated)
+}
+
+func (s *ACMEServer) getOrder(rw http.ResponseWriter, req *acmeRequest, id string, status int) {
+	order, ok := s.orders[id]
+	if !ok || order.AccountURL != req.AccountURL {
+		s.writeProblem(rw, &acmeProblem{http.StatusNotFound, acmeProblemMalformed, "order not found"})
+		return
+	}
+
+	var identifiers []map[string]string
+	for _, domain := range order.Identifiers {
+		identifiers = append(identifiers, map[string]string{"type": "dns", "value": domain})
+	}
+	var authorizations []string
+	for _, authzID := range order.AuthzIDs {
+		authorizations = append(authorizations, s.url("/authz/"+authzID))
+	}
+	body := map[string]interface{}{
+		"status":         s.orderStatus(order),
+		"identifiers":    identifiers,
+		"authorizations": authorizations,
+		"finalize":       s.url("/finalize/" + id),
+	}
+	if order.Cert != nil {
+		body["certificate"] = s.url("/cert/" + id)
+	}
+	s.writeJSON(rw, status, s.url("/order/"+id), body)
+}
+
+func (s *ACMEServer) orderStatus(order *acmeOrder) string {
+	if order.Cert != nil {
+		return acme.StatusValid
+	}
+	status := acme.StatusReady
+	for _, authzID := range order.AuthzIDs {
+		switch s.authzs[authzID].Status {
+		case acme.StatusInvalid:
+			return acme.StatusInvalid
+		case acme.StatusPending:
+			status = acme.StatusPending
+		}
+	}
+	return status
+}
+
+func (s *ACMEServer) getAuthz(rw http.ResponseWriter, req *acmeRequest, id string) {
+	authz, ok := s.authzs[id]
+	if !ok {
+		s.writeProblem(rw, &acmeProblem{http.StatusNotFound, acmeProblemMalformed, "authorization not found"})
+		return
+	}
+	s.writeJSON(rw, http.StatusOK, "", map[string]interface{}{
+		"identifier": map[string]string{"type": "dns", "value": authz.Domain},
+		"status":     authz.Status,
+		"challenges": []interface{}{s.challengeJSON(authz.ChallengeID)},
+	})
+}
+
+func (s *ACMEServer) challengeJSON(id string) map[string]interface{} {
+	chal := s.challenges[id]
+	body := map[string]interface{}{
+		"type":   "http-01",
+		"url":    s.url("/challenge/" + id),
+		"token":  chal.Token,
+		"status": chal.Status,
+	}
+	if chal.Error != "" {
+		body["error"] = map[string]interface{}{
+			"type":   acmeProblemUnauthorized,
+			"detail": chal.Error,
+		}
+	}
+	return body
+}
+
+func (s *ACMEServer) acceptChallenge(rw http.ResponseWriter, req *acmeRequest, id string) {
+	chal, ok := s.challenges[id]
+	if !ok {
+		s.writeProblem(rw, &acmeProblem{http.StatusNotFound, acmeProblemMalformed, "challenge not found"})
+		return
+	}
+
+	// Challenges are validated synchronously when accepted.
+	if len(req.Payload) > 0 && chal.Status == acme.StatusPending {
+		authz := s.authzs[chal.AuthzID]
+		if err := s.validateChallenge(authz.Domain, chal.Token, req.Key); err != nil {
+			chal.Status = acme.StatusInvalid
+			chal.Error = err.Error()
+			authz.Status = acme.StatusInvalid
+		} else {
+			chal.Status = acme.StatusValid
+			authz.Status = acme.StatusValid
+		}
+	}
+
+	s.writeJSON(rw, http.StatusOK, "", s.challengeJSON(id))
+}
+
+func (s *ACMEServer) validateChallenge(domain string, token string, key *ecdsa.PublicKey) error {
+	thumbprint, err := acme.JWKThumbprint(key)
+	if err != nil {
+		return err
+	}
+
+	httpReq, err := http.NewRequest(http.MethodGet, "http://"+s.ChallengeAddr+"/.well-known/acme-challenge/"+token, nil)
+	if err != nil {
+		return err
+	}
+	httpReq.Host = domain
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	if strings.TrimSpace(string(body)) != token+"."+thumbprint {
+		return fmt.Errorf("unexpected key authorization %q", body)
+	}
+	return nil
+}
+
+func (s *ACMEServer) finalizeOrder(rw http.ResponseWriter, req *acmeRequest, id string) {
+	order, ok := s.orders[id]
+	if !ok || order.AccountURL != req.AccountURL {
+		s.writeProblem(rw, &acmeProblem{http.StatusNotFound, acmeProblemMalformed, "order not found"})
+		return
+	}
+	if s.orderStatus(order) != acme.StatusReady {
+		s.writeProblem(rw, &acmeProblem{http.StatusForbidden, acmeProblemOrderNotReady, "order is not ready"})
+		return
+	}
+
+	var payload struct {
+		CSR string `json:"csr"`
+	}
+	if err := json.Unmarshal(req.Payload, &payload); err != nil {
+		s.writeProblem(rw, &acmeProblem{http.StatusBadRequest, acmeProblemMalformed, "invalid request"})
+		return
+	}
+	csrDER, err := base64.RawURLEncoding.DecodeString(payload.CSR)
+	if err != nil {
+		s.writeProblem(rw, &acmeProblem{http.StatusBadRequest, acmeProblemBadCSR, "invalid CSR encoding"})
+		return
+	}
+	csr, err := x509.ParseCertificateRequest(csrDER)
+	if err == nil {
+		err = csr.CheckSignature()
+	}
+	if err != nil {
+		s.writeProblem(rw, &acmeProblem{http.StatusBadRequest, acmeProblemBadCSR, err.Error()})
+		return
+	}
+
+	dnsNames := append([]string{}, csr.DNSNames...)
+	identifiers := append([]string{}, order.Identifiers...)
+	sort.Strings(dnsNames)
+	sort.Strings(identifiers)
+	if strings.Join(dnsNames, ",") != strings.Join(identifiers, ",") {
+		s.writeProblem(rw, &acmeProblem{http.StatusBadRequest, acmeProblemBadCSR, "CSR does not match order identifiers"})
+		return
+	}
+
+	now := s.Now()
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(int64(s.nextID + 1)),
+		Subject:      pkix.Name{CommonName: order.Identifiers[0]},
+		DNSNames:     order.Identifiers,
+		NotBefore:    now.Add(-time.Minute),
+		NotAfter:     now.Add(s.CertificateLifetime),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, s.caCert, csr.PublicKey, s.caKey)
+	if err != nil {
+		s.writeProblem(rw, &acmeProblem{http.StatusInternalServerError, acmeProblemMalformed, err.Error()})
+		return
+	}
+	order.Cert = der
+
+	s.getOrder(rw, req, id, http.StatusOK)
+}
+
+func (s *ACMEServer) getCert(rw http.ResponseWriter, req *acmeRequest, id string) {
+	order, ok := s.orders[id]
+	if !ok || order.AccountURL != req.AccountURL || order.Cert == nil {
+		s.writeProblem(rw, &acmeProblem{http.StatusNotFound, acmeProblemMalformed, "certificate not found"})
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/pem-certificate-chain")
+	rw.WriteHeader(http.StatusOK)
+	_ = pem.Encode(rw, &pem.Block{Type: "CERTIFICATE", Bytes: order.Cert})
+	_ = pem.Encode(rw, &pem.Block{Type: "CERTIFICATE", Bytes: s.caCert.Raw})
+}
+
+// verifyRequest verifies the JWS-signed request, and returns the account URL
+// of the signing key.
+func (s *ACMEServer) verifyRequest(r *http.Request) (*acmeRequest, *acmeProblem) {
+	malformed := func(detail string) (*acmeRequest, *acmeProblem) {
+		return nil, &acmeProblem{http.StatusBadRequest, acmeProblemMalformed, detail}
+	}
+
+	var body struct {
+		Protected string `json:"protected"`
+		Payload   string `json:"payload"`
+		Signature string `json:"signature"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return malformed("invalid JWS")
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(body.Protected)
+	if err != nil {
+		return malformed("invalid JWS header")
+	}
+	var header struct {
+		Alg   string          `json:"alg"`
+		JWK   json.RawMessage `json:"jwk"`
+		KID   string          `json:"kid"`
+		Nonce string          `json:"nonce"`
+		URL   string          `json:"url"`
+	}
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return malformed("invalid JWS header")
+	}
+	if header.Alg != "ES256" {
+		return malformed("unsupported algorithm")
+	}
+	if !s.nonces[header.Nonce] {
+		return nil, &acmeProblem{http.StatusBadRequest, acmeProblemBadNonce, "invalid nonce"}
+	}
+	delete(s.nonces, header.Nonce)
+	if header.URL != s.url(r.URL.Path) {
+		return malformed("URL mismatch")
+	}
+
+	req := &acmeRequest{}
+	switch {
+	case len(header.JWK) > 0 && header.KID == "":
+		key, err := parseJWK(header.JWK)
+		if err != nil {
+			return malformed(err.Error())
+		}
+		thumbprint, err := acme.JWKThumbprint(key)
+		if err != nil {
+			return malformed(err.Error())
+		}
+		req.Key = key
+		req.AccountURL = s.url("/account/" + thumbprint)
+	case len(header.JWK) == 0 && header.KID != "":
+		key, ok := s.accounts[header.KID]
+		if !ok {
+			return nil, &acmeProblem{http.StatusUnauthorized, acmeProblemUnauthorized, "account not found"}
+		}
+		req.Key = key
+		req.AccountURL = header.KID
+	default:
+		return malformed("exactly one of jwk and kid is required")
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(body.Signature)
+	if err != nil || len(sig) != 64 {
+		return malformed("invalid signature")
+	}
+	hash := sha256.Sum256([]byte(body.Protected + "." + body.Payload))
+	sigR := new(big.Int).SetBytes(sig[:32])
+	sigS := new(big.Int).SetBytes(sig[32:])
+	if !ecdsa.Verify(req.Key, hash[:], sigR, sigS) {
+		return nil, &acmeProblem{http.StatusUnauthorized, acmeProblemUnauthorized, "invalid signature"}
+	}
+
+	req.Payload, err = base64.RawURLEncoding.DecodeString(body.Payload)
+	if err != nil {
+		return malformed("invalid payload")
+	}
+	return req, nil
+}
+
+func parseJWK(data []byte) (*ecdsa.PublicKey, error) {
+	var jwk struct {
+		Kty string `json:"kty"`
+		Crv string `json:"crv"`
+		X   string `json:"x"`
+		Y   string `json:"y"`
+	}
+	if err := json.Unmarshal(data, &jwk); err != nil {
+		return nil, err
+	}
+	if jwk.Kty != "EC" || jwk.Crv != "P-256" {
+		return nil, errors.New("unsupported key")
+	}
+	x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+	if err != nil {
+		return nil, err
+	}
+	y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+	if err != nil {
+		return nil, err
+	}
+	return &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(x),
+		Y:     new(big.Int).SetBytes(y),
+	}, nil
+}
+
+func (s *ACMEServer) newID() string {
+	s.nextID++
+	return fmt.Sprintf("%d", s.nextID)
+}
+
+func (s *ACMEServer) newNonce() string {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		panic(err)
+	}
+	n := base64.RawURLEncoding.EncodeToString(nonce)
+	s.nonces[n] = true
+	return n
+}
+
+func (s *ACMEServer) url(path string) string {
+	return s.server.URL + path
+}
+
+func (s *ACMEServer) writeJSON(rw http.ResponseWriter, status int, location string, body interface{}) {
+	if location != "" {
+		rw.Header().Set("Location", location)
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	_ = json.NewEncoder(rw).Encode(body)
+}
+
+func (s *ACMEServer) writeProblem(rw http.ResponseWriter, problem *acmeProblem) {
+	rw.Header().Set("Content-Type", "application/problem+json")
+	rw.WriteHeader(problem.Status)
+	_ = json.NewEncoder(rw).Encode(map[string]interface{}{
+		"type":   problem.Type,
+		"detail": problem.Detail,
+	})
+}
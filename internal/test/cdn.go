@@ -0,0 +1,155 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer/cdn"
+)
+
+type cdnDistribution struct {
+	cdn.Distribution
+	CreateTime time.Time
+	DeleteTime *time.Time
+}
+
+// CDNServer is an in-process fake CDN API server.
+type CDNServer struct {
+	Now        func() time.Time
+	DeployTime time.Duration
+	DeleteTime time.Duration
+	Token      string
+
+	server        *httptest.Server
+	lock          sync.Mutex
+	nextID        int
+	distributions map[string]*cdnDistribution
+}
+
+func NewCDNServer() *CDNServer {
+	s := &CDNServer{
+		Now:           time.Now,
+		DeployTime:    time.Second * 1,
+		DeleteTime:    time.Second * 1,
+		Token:         "cdn-token",
+		distributions: map[string]*cdnDistribution{},
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+func (s *CDNServer) URL() string {
+	return s.server.URL
+}
+
+func (s *CDNServer) Close() {
+	s.server.Close()
+}
+
+// Distribution returns the current state of distribution of domain.
+func (s *CDNServer) Distribution(domain string) *cdn.Distribution {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	dist := s.lookup(domain)
+	if dist == nil {
+		return nil
+	}
+	d := dist.Distribution
+	return &d
+}
+
+func (s *CDNServer) lookup(domain string) *cdnDistribution {
+	dist, ok := s.distributions[domain]
+	if !ok {
+		return nil
+	}
+
+	now := s.Now()
+	if dist.DeleteTime != nil {
+		if !now.Before(dist.DeleteTime.Add(s.DeleteTime)) {
+			delete(s.distributions, domain)
+			return nil
+		}
+		dist.Status = cdn.DistributionStatusDeleting
+	} else if !now.Before(dist.CreateTime.Add(s.DeployTime)) {
+		dist.Status = cdn.DistributionStatusDeployed
+	}
+	return dist
+}
+
+func (s *CDNServer) serveHTTP(rw http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer "+s.Token {
+		http.Error(rw, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/distributions")
+	switch {
+	case r.Method == "POST" && path == "":
+		var body cdn.Distribution
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if s.lookup(body.Domain) != nil {
+			http.Error(rw, "distribution already exists", http.StatusConflict)
+			return
+		}
+
+		s.nextID++
+		id := fmt.Sprintf("dist-%d", s.nextID)
+		dist := &cdnDistribution{
+			Distribution: cdn.Distribution{
+				ID:     id,
+				Domain: body.Domain,
+				Origin: body.Origin,
+				Status: cdn.DistributionStatusPending,
+				CNAME:  id + ".cdn.test",
+			},
+			CreateTime: s.Now(),
+		}
+		s.distributions[body.Domain] = dist
+		writeJSON(rw, http.StatusCreated, dist.Distribution)
+
+	case r.Method == "GET" && strings.HasPrefix(path, "/"):
+		dist := s.lookup(strings.TrimPrefix(path, "/"))
+		if dist == nil {
+			http.NotFound(rw, r)
+			return
+		}
+		writeJSON(rw, http.StatusOK, dist.Distribution)
+
+	case r.Method == "DELETE" && strings.HasPrefix(path, "/"):
+		id := strings.TrimPrefix(path, "/")
+		for domain, dist := range s.distributions {
+			if dist.ID != id || s.lookup(domain) == nil {
+				continue
+			}
+			if dist.DeleteTime == nil {
+				now := s.Now()
+				dist.DeleteTime = &now
+			}
+			rw.WriteHeader(http.StatusAccepted)
+			return
+		}
+		http.NotFound(rw, r)
+
+	default:
+		http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func writeJSON(rw http.ResponseWriter, status int, body interface{}) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	_ = json.NewEncoder(rw).Encode(body)
+}
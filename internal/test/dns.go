@@ -0,0 +1,226 @@
+package test
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	// flags in the fourth byte of DNS message header
+	dnsFlagAuthenticData    = 0x20
+	dnsFlagCheckingDisabled = 0x10
+)
+
+type dnsRecordKey struct {
+	Name string
+	Type dnsmessage.Type
+}
+
+// DNSServer is an in-process DNS server serving static records over UDP.
+type DNSServer struct {
+	Addr string
+
+	conn          net.PacketConn
+	mutex         sync.RWMutex
+	records       map[dnsRecordKey][]string
+	bogusNames    map[string]bool
+	authenticData bool
+}
+
+func NewDNSServer() (*DNSServer, error) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &DNSServer{
+		Addr:       conn.LocalAddr().String(),
+		conn:       conn,
+		records:    map[dnsRecordKey][]string{},
+		bogusNames: map[string]bool{},
+	}
+	go s.serve()
+	return s, nil
+}
+
+func (s *DNSServer) Close() error {
+	return s.conn.Close()
+}
+
+// SetRecords replaces records of the name with the specified type. Supported
+// types are A, AAAA, CNAME, NS and TXT.
+func (s *DNSServer) SetRecords(name string, recordType string, values ...string) {
+	var t dnsmessage.Type
+	switch recordType {
+	case "A":
+		t = dnsmessage.TypeA
+	case "AAAA":
+		t = dnsmessage.TypeAAAA
+	case "CNAME":
+		t = dnsmessage.TypeCNAME
+	case "NS":
+		t = dnsmessage.TypeNS
+	case "TXT":
+		t = dnsmessage.TypeTXT
+	default:
+		panic(fmt.Sprintf("unsupported DNS record type '%s'", recordType))
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	key := dnsRecordKey{Name: canonicalName(name), Type: t}
+	if len(values) == 0 {
+		delete(s.records, key)
+	} else {
+		s.records[key] = values
+	}
+}
+
+// SetAuthenticData sets whether responses are marked as DNSSEC validated.
+func (s *DNSServer) SetAuthenticData(authenticData bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.authenticData = authenticData
+}
+
+// SetBogus sets whether records of the name fail DNSSEC validation, like a
+// validating resolver, server failure is responded unless checking is
+// disabled in the query.
+func (s *DNSServer) SetBogus(name string, bogus bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.bogusNames[canonicalName(name)] = bogus
+}
+
+func (s *DNSServer) serve() {
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		resp, err := s.handle(buf[:n])
+		if err != nil {
+			continue
+		}
+		_, _ = s.conn.WriteTo(resp, addr)
+	}
+}
+
+func (s *DNSServer) handle(req []byte) ([]byte, error) {
+	var p dnsmessage.Parser
+	header, err := p.Start(req)
+	if err != nil {
+		return nil, err
+	}
+	q, err := p.Question()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	checkingDisabled := len(req) > 3 && req[3]&dnsFlagCheckingDisabled != 0
+
+	rcode := dnsmessage.RCodeSuccess
+	if !s.hasName(canonicalName(q.Name.String())) {
+		rcode = dnsmessage.RCodeNameError
+	} else if s.bogusNames[canonicalName(q.Name.String())] && !checkingDisabled {
+		rcode = dnsmessage.RCodeServerFailure
+	}
+
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{
+		ID:                 header.ID,
+		Response:           true,
+		Authoritative:      true,
+		RecursionDesired:   header.RecursionDesired,
+		RecursionAvailable: true,
+		RCode:              rcode,
+	})
+	b.EnableCompression()
+	if err := b.StartQuestions(); err != nil {
+		return nil, err
+	}
+	if err := b.Question(q); err != nil {
+		return nil, err
+	}
+	if err := b.StartAnswers(); err != nil {
+		return nil, err
+	}
+
+	name := canonicalName(q.Name.String())
+	for i := 0; rcode == dnsmessage.RCodeSuccess && i < 8; i++ {
+		if q.Type != dnsmessage.TypeCNAME {
+			if cnames := s.records[dnsRecordKey{Name: name, Type: dnsmessage.TypeCNAME}]; len(cnames) > 0 {
+				target := canonicalName(cnames[0])
+				if err := s.addAnswer(&b, name, dnsmessage.TypeCNAME, target); err != nil {
+					return nil, err
+				}
+				name = target
+				continue
+			}
+		}
+
+		for _, value := range s.records[dnsRecordKey{Name: name, Type: q.Type}] {
+			if err := s.addAnswer(&b, name, q.Type, value); err != nil {
+				return nil, err
+			}
+		}
+		break
+	}
+
+	resp, err := b.Finish()
+	if err != nil {
+		return nil, err
+	}
+	if s.authenticData && rcode == dnsmessage.RCodeSuccess {
+		resp[3] |= dnsFlagAuthenticData
+	}
+	return resp, nil
+}
+
+func (s *DNSServer) hasName(name string) bool {
+	for key := range s.records {
+		if key.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *DNSServer) addAnswer(b *dnsmessage.Builder, name string, t dnsmessage.Type, value string) error {
+	h := dnsmessage.ResourceHeader{
+		Name:  dnsmessage.MustNewName(name),
+		Class: dnsmessage.ClassINET,
+	}
+	switch t {
+	case dnsmessage.TypeA:
+		var a [4]byte
+		copy(a[:], net.ParseIP(value).To4())
+		return b.AResource(h, dnsmessage.AResource{A: a})
+	case dnsmessage.TypeAAAA:
+		var aaaa [16]byte
+		copy(aaaa[:], net.ParseIP(value).To16())
+		return b.AAAAResource(h, dnsmessage.AAAAResource{AAAA: aaaa})
+	case dnsmessage.TypeCNAME:
+		return b.CNAMEResource(h, dnsmessage.CNAMEResource{CNAME: dnsmessage.MustNewName(canonicalName(value))})
+	case dnsmessage.TypeNS:
+		return b.NSResource(h, dnsmessage.NSResource{NS: dnsmessage.MustNewName(canonicalName(value))})
+	case dnsmessage.TypeTXT:
+		return b.TXTResource(h, dnsmessage.TXTResource{TXT: []string{value}})
+	}
+	return nil
+}
+
+func canonicalName(name string) string {
+	name = strings.ToLower(name)
+	if !strings.HasSuffix(name, ".") {
+		name += "."
+	}
+	return name
+}
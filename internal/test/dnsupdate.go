@@ -0,0 +1,163 @@
+package test
+
+import (
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/miekg/dns"
+)
+
+type dnsUpdateRecordKey struct {
+	Name string
+	Type uint16
+}
+
+// DNSUpdateServer is a local authoritative DNS server accepting RFC 2136
+// dynamic updates signed with TSIG.
+type DNSUpdateServer struct {
+	Addr       string
+	Zone       string
+	KeyName    string
+	KeySecret  string
+	server     *dns.Server
+	lock       sync.Mutex
+	records    map[dnsUpdateRecordKey][]dns.RR
+	numUpdates int
+}
+
+func NewDNSUpdateServer(zone string, keyName string, keySecret string) (*DNSUpdateServer, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &DNSUpdateServer{
+		Addr:      l.Addr().String(),
+		Zone:      dns.Fqdn(strings.ToLower(zone)),
+		KeyName:   dns.Fqdn(strings.ToLower(keyName)),
+		KeySecret: keySecret,
+		records:   map[dnsUpdateRecordKey][]dns.RR{},
+	}
+
+	started := make(chan struct{})
+	s.server = &dns.Server{
+		Listener:          l,
+		Net:               "tcp",
+		Handler:           dns.HandlerFunc(s.serveDNS),
+		TsigSecret:        map[string]string{s.KeyName: keySecret},
+		NotifyStartedFunc: func() { close(started) },
+		// default accept function rejects dynamic updates
+		MsgAcceptFunc: func(dns.Header) dns.MsgAcceptAction { return dns.MsgAccept },
+	}
+	go func() {
+		_ = s.server.ActivateAndServe()
+	}()
+	<-started
+
+	return s, nil
+}
+
+func (s *DNSUpdateServer) Close() {
+	_ = s.server.Shutdown()
+}
+
+// Records returns values of records of the name and type.
+func (s *DNSUpdateServer) Records(name string, rrType string) []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	key := dnsUpdateRecordKey{Name: dns.Fqdn(strings.ToLower(name)), Type: dns.StringToType[rrType]}
+	var values []string
+	for _, rr := range s.records[key] {
+		switch rr := rr.(type) {
+		case *dns.A:
+			values = append(values, rr.A.String())
+		case *dns.AAAA:
+			values = append(values, rr.AAAA.String())
+		case *dns.CNAME:
+			values = append(values, strings.TrimSuffix(rr.Target, "."))
+		case *dns.TXT:
+			values = append(values, strings.Join(rr.Txt, ""))
+		}
+	}
+	return values
+}
+
+// NumUpdates returns the number of accepted updates.
+func (s *DNSUpdateServer) NumUpdates() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.numUpdates
+}
+
+func (s *DNSUpdateServer) serveDNS(w dns.ResponseWriter, req *dns.Msg) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	resp := new(dns.Msg)
+	resp.SetReply(req)
+	if t := req.IsTsig(); t != nil {
+		resp.SetTsig(t.Hdr.Name, t.Algorithm, t.Fudge, int64(t.TimeSigned))
+	}
+
+	switch {
+	case req.Opcode != dns.OpcodeUpdate:
+		resp.Rcode = dns.RcodeNotImplemented
+	case req.IsTsig() == nil || w.TsigStatus() != nil:
+		resp.Rcode = dns.RcodeNotAuth
+	case len(req.Question) != 1 || strings.ToLower(req.Question[0].Name) != s.Zone:
+		resp.Rcode = dns.RcodeNotZone
+	default:
+		for _, rr := range req.Ns {
+			if !dns.IsSubDomain(s.Zone, strings.ToLower(rr.Header().Name)) {
+				resp.Rcode = dns.RcodeNotZone
+				_ = w.WriteMsg(resp)
+				return
+			}
+		}
+		for _, rr := range req.Ns {
+			s.applyUpdate(rr)
+		}
+		s.numUpdates++
+	}
+	_ = w.WriteMsg(resp)
+}
+
+func (s *DNSUpdateServer) applyUpdate(rr dns.RR) {
+	hdr := rr.Header()
+	key := dnsUpdateRecordKey{Name: strings.ToLower(hdr.Name), Type: hdr.Rrtype}
+	switch hdr.Class {
+	case dns.ClassANY:
+		if hdr.Rrtype == dns.TypeANY {
+			for k := range s.records {
+				if k.Name == key.Name {
+					delete(s.records, k)
+				}
+			}
+		} else {
+			delete(s.records, key)
+		}
+	case dns.ClassNONE:
+		var rrs []dns.RR
+		for _, existing := range s.records[key] {
+			if !dns.IsDuplicate(existing, withClass(rr, dns.ClassINET)) {
+				rrs = append(rrs, existing)
+			}
+		}
+		s.records[key] = rrs
+	default:
+		for _, existing := range s.records[key] {
+			if dns.IsDuplicate(existing, rr) {
+				return
+			}
+		}
+		s.records[key] = append(s.records[key], dns.Copy(rr))
+	}
+}
+
+func withClass(rr dns.RR, class uint16) dns.RR {
+	rr = dns.Copy(rr)
+	rr.Header().Class = class
+	return rr
+}
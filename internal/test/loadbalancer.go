@@ -2,7 +2,6 @@ package test
 
 import (
 	"context"
-	"fmt"
 	"time"
 
 	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
@@ -34,13 +33,22 @@ func (p *LoadBalancer) Provision(ctx context.Context, domain *domainv1beta1.Cust
 		p.ProvisionRequests[domain.Name] = reqTime
 	}
 
+	// Load balancers of other providers are served at another address.
+	providerType, address := "test", "127.0.0.1"
+	if domain.Spec.LoadBalancerProvider != nil && *domain.Spec.LoadBalancerProvider != "test" {
+		providerType, address = *domain.Spec.LoadBalancerProvider, "127.0.0.2"
+	}
+
 	if p.Now().Before(reqTime.Add(p.ProvisionTime)) {
-		return "test", nil, nil
+		return providerType, nil, nil
 	}
 
-	return "test", &loadbalancer.ProvisionResult{DNSRecords: []loadbalancer.DNSRecord{
-		{Name: domain.Name, Type: "A", Value: "127.0.0.1"},
-	}}, nil
+	return providerType, &loadbalancer.ProvisionResult{
+		Region: domain.Spec.Region,
+		DNSRecords: []loadbalancer.DNSRecord{
+			{Name: domain.Name, Type: "A", Value: address},
+		},
+	}, nil
 }
 
 func (p *LoadBalancer) Release(ctx context.Context, domain *domainv1beta1.CustomDomain) (ok bool, err error) {
@@ -62,3 +70,7 @@ func (p *LoadBalancer) Release(ctx context.Context, domain *domainv1beta1.Custom
 	delete(p.ProvisionRequests, domain.Name)
 	return true, nil
 }
+
+func (p *LoadBalancer) ValidateClass(class *domainv1beta1.LoadBalancerClass) error {
+	return nil
+}
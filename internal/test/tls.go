@@ -2,6 +2,7 @@ package test
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -19,6 +20,9 @@ type TLSProvider struct {
 	Now               func() time.Time
 	ProvisionTime     time.Duration
 	ProvisionRequests map[types.NamespacedName]time.Time
+
+	mutex         sync.Mutex
+	renewalErrors map[types.NamespacedName]error
 }
 
 func NewTLSProvider(client client.Client) *TLSProvider {
@@ -27,9 +31,18 @@ func NewTLSProvider(client client.Client) *TLSProvider {
 		Now:               time.Now,
 		ProvisionTime:     time.Second * 1,
 		ProvisionRequests: map[types.NamespacedName]time.Time{},
+		renewalErrors:     map[types.NamespacedName]error{},
 	}
 }
 
+// SetRenewalError makes renewal of issued certificate of the registration
+// fail with err, or succeed if err is nil.
+func (p *TLSProvider) SetRenewalError(namespace, name string, err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.renewalErrors[types.NamespacedName{Namespace: namespace, Name: name}] = err
+}
+
 func (p *TLSProvider) Provision(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (*tls.ProvisionResult, error) {
 	n := types.NamespacedName{Namespace: reg.Namespace, Name: reg.Name}
 	reqTime, ok := p.ProvisionRequests[n]
@@ -57,7 +70,11 @@ func (p *TLSProvider) Provision(ctx context.Context, reg *domainv1beta1.CustomDo
 			return nil, err
 		}
 	}
-	return &tls.ProvisionResult{CertSecretName: secret.Name}, nil
+
+	p.mutex.Lock()
+	err := p.renewalErrors[n]
+	p.mutex.Unlock()
+	return &tls.ProvisionResult{CertSecretName: secret.Name}, err
 }
 
 func (p *TLSProvider) Release(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (ok bool, err error) {
@@ -3,7 +3,10 @@ package test
 import (
 	"context"
 	"fmt"
+	"sync"
 
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/internal"
 	"github.com/skygeario/k8s-controller/pkg/domain/verification"
 )
 
@@ -12,29 +15,92 @@ func DomainKeyGenerator() string {
 }
 
 type DomainChecker struct {
-	Records map[string][]string
+	*internal.DomainVerifier
+
+	lock    sync.RWMutex
+	records map[string][]string
 }
 
 func NewDomainChecker() *DomainChecker {
 	return &DomainChecker{
-		Records: map[string][]string{},
+		DomainVerifier: &internal.DomainVerifier{
+			CNAME: &verification.CNAMEConfig{Target: "verify.test"},
+		},
+		records: map[string][]string{},
 	}
 }
 
 func (c *DomainChecker) Reset() {
-	c.Records = map[string][]string{}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.records = map[string][]string{}
+}
+
+// SetRecords replaces the record values of the name, or URL for HTTP
+// verification.
+func (c *DomainChecker) SetRecords(name string, values ...string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if len(values) == 0 {
+		delete(c.records, name)
+	} else {
+		c.records[name] = values
+	}
 }
 
-func (c *DomainChecker) VerifyDomain(ctx context.Context, domain string, token string) error {
-	name, err := verification.MakeDNSRecordName(domain)
+func (c *DomainChecker) lookup(name string) []string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.records[name]
+}
+
+func (c *DomainChecker) VerifyDomain(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration, token string) ([]domainv1beta1.CustomDomainVerificationResult, error) {
+	if verification.MethodOf(reg) == domainv1beta1.VerificationMethodHTTP {
+		url := verification.MakeHTTPURL(reg.Spec.DomainName, reg.Namespace)
+		for _, rtoken := range c.lookup(url) {
+			if rtoken == token {
+				return nil, nil
+			}
+		}
+		return nil, fmt.Errorf("verification token not found")
+	}
+
+	records, err := c.MakeDNSRecords(reg, token)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	for _, record := range records {
+		for _, value := range c.lookup(record.Name) {
+			if value == record.Value {
+				return nil, nil
+			}
+		}
 	}
+	return nil, fmt.Errorf("verification DNS record not found")
+}
 
-	for _, rtoken := range c.Records[name] {
-		if rtoken == token {
-			return nil
+// CheckDNSRecords requires all records to be configured, like the real
+// check.
+func (c *DomainChecker) CheckDNSRecords(ctx context.Context, domain string, records []domainv1beta1.CustomDomainDNSRecord) error {
+	if len(records) == 0 {
+		return fmt.Errorf("no load balancer DNS records for the domain")
+	}
+	for _, record := range records {
+		name := record.Name
+		if name == "@" {
+			name = domain
+		}
+		found := false
+		for _, value := range c.lookup(name) {
+			if value == record.Value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("domain DNS records not found")
 		}
 	}
-	return fmt.Errorf("verification DNS record not found")
+	return nil
 }
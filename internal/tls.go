@@ -8,17 +8,20 @@ import (
 
 	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
 	"github.com/skygeario/k8s-controller/pkg/domain/tls"
+	"github.com/skygeario/k8s-controller/pkg/domain/tls/acme"
 	"github.com/skygeario/k8s-controller/pkg/domain/tls/certmanager"
 	"github.com/skygeario/k8s-controller/pkg/domain/tls/usersecret"
 )
 
 const (
 	tlsCertManager string = "cert-manager"
+	tlsACME        string = "acme"
 	tlsUserSecret  string = "user-secret"
 )
 
 type TLSProvider struct {
 	CertManager *certmanager.Provider
+	ACME        *acme.Provider
 	UserSecret  *usersecret.Provider
 }
 
@@ -31,18 +34,33 @@ func NewTLSProvider(client client.Client, config Config) (*TLSProvider, error) {
 		if err != nil {
 			return nil, fmt.Errorf("cannot create cert-manager provider: %w", err)
 		}
-	} else {
-		return nil, fmt.Errorf("cert-manager config is missing")
+	}
+
+	var acmeProvider *acme.Provider
+	if config.ACME != nil {
+		ingressProvider, err := NewIngressProvider(config)
+		if err != nil {
+			return nil, err
+		}
+		acmeProvider, err = acme.NewProvider(client, ingressProvider, *config.ACME)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create ACME provider: %w", err)
+		}
+	}
+
+	if certManager == nil && acmeProvider == nil {
+		return nil, fmt.Errorf("either cert-manager or ACME config is required")
 	}
 
 	var userSecret *usersecret.Provider
-	userSecret, err = usersecret.NewProvider()
+	userSecret, err = usersecret.NewProvider(client)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create user secret certificate provider: %w", err)
 	}
 
 	return &TLSProvider{
 		CertManager: certManager,
+		ACME:        acmeProvider,
 		UserSecret:  userSecret,
 	}, nil
 }
@@ -87,15 +105,25 @@ func (p *TLSProvider) Release(ctx context.Context, reg *domainv1beta1.CustomDoma
 }
 
 func (p *TLSProvider) allProviders() map[string]tls.Provider {
-	return map[string]tls.Provider{
-		tlsCertManager: p.CertManager,
-		tlsUserSecret:  p.UserSecret,
+	providers := map[string]tls.Provider{
+		tlsUserSecret: p.UserSecret,
 	}
+	if p.CertManager != nil {
+		providers[tlsCertManager] = p.CertManager
+	}
+	if p.ACME != nil {
+		providers[tlsACME] = p.ACME
+	}
+	return providers
 }
 
 func (p *TLSProvider) selectProvider(reg *domainv1beta1.CustomDomainRegistration) (string, tls.Provider, error) {
 	if reg.Spec.DomainConfig.CertSecretName != nil {
 		return tlsUserSecret, p.UserSecret, nil
 	}
-	return tlsCertManager, p.CertManager, nil
+	// cert-manager is preferred if both are configured
+	if p.CertManager != nil {
+		return tlsCertManager, p.CertManager, nil
+	}
+	return tlsACME, p.ACME, nil
 }
@@ -0,0 +1,148 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/verification"
+)
+
+type DomainVerifier struct {
+	Resolver *verification.Resolver
+	Quorum   *verification.Quorum
+	HTTP     *verification.HTTPConfig
+	CNAME    *verification.CNAMEConfig
+	Format   verification.RecordFormat
+	// DisallowParentZoneRecords requires verification DNS records of
+	// subdomains to be placed under the exact domain.
+	DisallowParentZoneRecords bool
+}
+
+func NewDomainVerifier(config Config) (*DomainVerifier, error) {
+	v := &DomainVerifier{}
+	resolverConfig := verification.ResolverConfig{}
+	if config.Verification != nil {
+		v.HTTP = config.Verification.HTTP
+		v.CNAME = config.Verification.CNAME
+		v.Format = verification.RecordFormat{
+			Prefix:   config.Verification.RecordPrefix,
+			TokenKey: config.Verification.TokenKey,
+		}
+		v.DisallowParentZoneRecords = config.Verification.DisallowParentZoneRecords
+		if config.Verification.Resolver != nil {
+			resolverConfig = *config.Verification.Resolver
+		}
+	}
+	if v.CNAME != nil && v.CNAME.Target == "" {
+		return nil, fmt.Errorf("CNAME verification target is missing")
+	}
+	if err := v.Format.Validate(); err != nil {
+		return nil, err
+	}
+
+	resolver, err := verification.NewResolver(resolverConfig)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create DNS resolver: %w", err)
+	}
+	v.Resolver = resolver
+
+	if resolverConfig.Quorum > 0 {
+		quorum, err := verification.NewQuorum(resolverConfig)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create resolver quorum: %w", err)
+		}
+		v.Quorum = quorum
+	}
+
+	return v, nil
+}
+
+func (v *DomainVerifier) recordDomain(reg *domainv1beta1.CustomDomainRegistration) (string, error) {
+	scope := verification.ScopeOf(reg)
+	if v.DisallowParentZoneRecords {
+		scope = domainv1beta1.VerificationRecordScopeExact
+	}
+	return verification.RecordDomain(reg.Spec.DomainName, scope)
+}
+
+func (v *DomainVerifier) MakeDNSRecords(reg *domainv1beta1.CustomDomainRegistration, token string) ([]domainv1beta1.CustomDomainDNSRecord, error) {
+	method := verification.MethodOf(reg)
+	if method == domainv1beta1.VerificationMethodHTTP {
+		// No DNS records required.
+		return nil, nil
+	}
+
+	recordDomain, err := v.recordDomain(reg)
+	if err != nil {
+		return nil, err
+	}
+
+	switch method {
+	case domainv1beta1.VerificationMethodTXT:
+		name := v.Format.MakeRecordName(recordDomain)
+		value := v.Format.MakeRecordValue(token)
+		return []domainv1beta1.CustomDomainDNSRecord{{Name: name, Type: "TXT", Value: value}}, nil
+	case domainv1beta1.VerificationMethodCNAME:
+		if v.CNAME == nil {
+			return nil, fmt.Errorf("CNAME verification is unavailable")
+		}
+		name := verification.MakeCNAMERecordName(recordDomain, token)
+		return []domainv1beta1.CustomDomainDNSRecord{{Name: name, Type: "CNAME", Value: v.CNAME.Target}}, nil
+	default:
+		return nil, fmt.Errorf("verification method '%s' is unavailable", method)
+	}
+}
+
+func (v *DomainVerifier) VerifyDomain(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration, token string) ([]domainv1beta1.CustomDomainVerificationResult, error) {
+	method := verification.MethodOf(reg)
+	if method == domainv1beta1.VerificationMethodHTTP {
+		if v.HTTP == nil {
+			return nil, fmt.Errorf("HTTP verification is unavailable")
+		}
+		return nil, verification.VerifyHTTP(ctx, reg.Spec.DomainName, reg.Namespace, token)
+	}
+
+	recordDomain, err := v.recordDomain(reg)
+	if err != nil {
+		return nil, fmt.Errorf("cannot lookup verification DNS record: %w", err)
+	}
+
+	var verify func(ctx context.Context, resolver *verification.Resolver) error
+	switch method {
+	case domainv1beta1.VerificationMethodTXT:
+		verify = func(ctx context.Context, resolver *verification.Resolver) error {
+			return verification.VerifyDomain(ctx, resolver, v.Format, recordDomain, token)
+		}
+	case domainv1beta1.VerificationMethodCNAME:
+		if v.CNAME == nil {
+			return nil, fmt.Errorf("CNAME verification is unavailable")
+		}
+		verify = func(ctx context.Context, resolver *verification.Resolver) error {
+			return verification.VerifyCNAME(ctx, resolver, recordDomain, token, v.CNAME.Target)
+		}
+	default:
+		return nil, fmt.Errorf("verification method '%s' is unavailable", method)
+	}
+
+	if v.Quorum == nil {
+		return nil, verify(ctx, v.Resolver)
+	}
+
+	quorumResults, err := v.Quorum.Verify(ctx, verify)
+	results := make([]domainv1beta1.CustomDomainVerificationResult, len(quorumResults))
+	for i, r := range quorumResults {
+		results[i] = domainv1beta1.CustomDomainVerificationResult{
+			Resolver: r.Resolver,
+			Verified: r.Err == nil,
+		}
+		if r.Err != nil {
+			results[i].Message = r.Err.Error()
+		}
+	}
+	return results, err
+}
+
+func (v *DomainVerifier) CheckDNSRecords(ctx context.Context, domain string, records []domainv1beta1.CustomDomainDNSRecord) error {
+	return verification.CheckDNSRecords(ctx, v.Resolver, domain, records)
+}
@@ -23,18 +23,22 @@ import (
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
-	cm "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha2"
-
 	// +kubebuilder:scaffold:imports
 
 	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
 	"github.com/skygeario/k8s-controller/controllers"
 	"github.com/skygeario/k8s-controller/internal"
+	"github.com/skygeario/k8s-controller/pkg/domain/publish/externaldns"
+	"github.com/skygeario/k8s-controller/pkg/domain/publish/rfc2136"
+	"github.com/skygeario/k8s-controller/pkg/domain/tls"
+	"github.com/skygeario/k8s-controller/pkg/domain/tls/acme"
 	"github.com/skygeario/k8s-controller/pkg/domain/verification"
 )
 
@@ -45,7 +49,6 @@ var (
 
 func init() {
 	_ = clientgoscheme.AddToScheme(scheme)
-	_ = cm.AddToScheme(scheme)
 
 	_ = domainv1beta1.AddToScheme(scheme)
 	// +kubebuilder:scaffold:scheme
@@ -78,6 +81,18 @@ func main() {
 		os.Exit(1)
 	}
 
+	if config.Verification != nil {
+		if config.Verification.ReverifyInterval != nil {
+			controllers.ReverificationInterval = config.Verification.ReverifyInterval.Duration
+		}
+		if config.Verification.GracePeriod != nil {
+			controllers.VerificationGracePeriod = config.Verification.GracePeriod.Duration
+		}
+		if config.Verification.KeyRotationPeriod != nil {
+			controllers.VerificationKeyRotationPeriod = config.Verification.KeyRotationPeriod.Duration
+		}
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:             scheme,
 		MetricsBindAddress: metricsAddr,
@@ -89,7 +104,7 @@ func main() {
 		os.Exit(1)
 	}
 
-	loadBalancer, err := internal.NewLoadBalancer(config)
+	loadBalancer, err := internal.NewLoadBalancer(mgr.GetClient(), config)
 	if err != nil {
 		setupLog.Error(err, "unable create load balancer")
 		os.Exit(1)
@@ -101,12 +116,90 @@ func main() {
 		os.Exit(1)
 	}
 
+	if tlsProvider.ACME != nil {
+		err = mgr.Add(&acme.ChallengeServer{
+			Provider: tlsProvider.ACME,
+			Addr:     config.ACME.ListenAddress,
+		})
+		if err != nil {
+			setupLog.Error(err, "unable create ACME challenge server")
+			os.Exit(1)
+		}
+	}
+
 	ingressProvider, err := internal.NewIngressProvider(config)
 	if err != nil {
 		setupLog.Error(err, "unable create ingress provider")
 		os.Exit(1)
 	}
 
+	domainVerifier, err := internal.NewDomainVerifier(config)
+	if err != nil {
+		setupLog.Error(err, "unable create domain verifier")
+		os.Exit(1)
+	}
+
+	var dnsPublisher controllers.DNSPublisher
+	if config.RFC2136 != nil {
+		publisher, err := rfc2136.NewProvider(*config.RFC2136)
+		if err != nil {
+			setupLog.Error(err, "unable create DNS publisher")
+			os.Exit(1)
+		}
+		dnsPublisher = publisher
+	}
+
+	var externalDNS *externaldns.Provider
+	if config.ExternalDNS != nil {
+		externalDNS, err = externaldns.NewProvider(*config.ExternalDNS)
+		if err != nil {
+			setupLog.Error(err, "unable create ExternalDNS provider")
+			os.Exit(1)
+		}
+	}
+
+	loadBalancerEvents := make(chan event.GenericEvent, 1)
+	onHealthChange := func() {
+		e := event.GenericEvent{Meta: &metav1.ObjectMeta{Name: "static-ip"}}
+		select {
+		case loadBalancerEvents <- e:
+		default:
+		}
+	}
+	if loadBalancer.StaticIP != nil && loadBalancer.StaticIP.HealthChecker != nil {
+		healthChecker := loadBalancer.StaticIP.HealthChecker
+		healthChecker.OnChange = onHealthChange
+		if err = mgr.Add(healthChecker); err != nil {
+			setupLog.Error(err, "unable create static IP health checker")
+			os.Exit(1)
+		}
+	}
+	// health checkers of load-balancer classes are run by the load balancer
+	loadBalancer.OnHealthChange = onHealthChange
+	if err = mgr.Add(loadBalancer); err != nil {
+		setupLog.Error(err, "unable create load balancer")
+		os.Exit(1)
+	}
+
+	var loadBalancerService *types.NamespacedName
+	if config.Service != nil {
+		loadBalancerService = &types.NamespacedName{Namespace: config.Service.Namespace, Name: config.Service.Name}
+	}
+
+	var httpVerification *verification.HTTPConfig
+	if config.Verification != nil && config.Verification.HTTP != nil {
+		httpVerification = config.Verification.HTTP
+		err = mgr.Add(&verification.TokenServer{
+			KubeClient:     mgr.GetClient(),
+			Addr:           httpVerification.ListenAddress,
+			TokenGenerator: verification.GenerateDomainToken,
+		})
+		if err != nil {
+			setupLog.Error(err, "unable create verification token server")
+			os.Exit(1)
+		}
+	}
+
 	if enableWebhooks {
 		if err = (&domainv1beta1.CustomDomainRegistration{}).SetupWebhookWithManager(mgr); err != nil {
 			setupLog.Error(err, "unable to create webhook", "webhook", "CustomDomainRegistration")
@@ -123,9 +216,12 @@ func main() {
 		Scheme:                     mgr.GetScheme(),
 		Now:                        metav1.Now,
 		VerificationTokenGenerator: verification.GenerateDomainToken,
-		DomainVerifier:             verification.VerifyDomain,
+		DomainVerifier:             domainVerifier,
 		TLSProvider:                tlsProvider,
 		IngressProvider:            ingressProvider,
+		DNSPublisher:               dnsPublisher,
+		Recorder:                   mgr.GetEventRecorderFor("customdomainregistration-controller"),
+		CertExpiryThresholds:       tls.ExpiryThresholds(config.CertExpiry),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "CustomDomainRegistration")
 		os.Exit(1)
@@ -136,11 +232,27 @@ func main() {
 		Scheme:                   mgr.GetScheme(),
 		Now:                      metav1.Now,
 		LoadBalancer:             loadBalancer,
+		LoadBalancerService:      loadBalancerService,
+		DNSChecker:               domainVerifier,
+		DNSPublisher:             dnsPublisher,
+		ExternalDNS:              externalDNS,
+		LoadBalancerEvents:       loadBalancerEvents,
 		VerificationKeyGenerator: verification.GenerateDomainKey,
+		IngressProvider:          ingressProvider,
+		HTTPVerification:         httpVerification,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "CustomDomain")
 		os.Exit(1)
 	}
+	if err = (&controllers.LoadBalancerClassReconciler{
+		Client:    mgr.GetClient(),
+		Log:       ctrl.Log.WithName("controllers").WithName("LoadBalancerClass"),
+		Scheme:    mgr.GetScheme(),
+		Validator: loadBalancer,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "LoadBalancerClass")
+		os.Exit(1)
+	}
 	// +kubebuilder:scaffold:builder
 
 	setupLog.Info("starting manager")
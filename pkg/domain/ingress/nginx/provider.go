@@ -1,6 +1,8 @@
 package nginx
 
 import (
+	"fmt"
+
 	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
 	networkingv1beta1 "k8s.io/api/networking/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -9,6 +11,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	"github.com/skygeario/k8s-controller/pkg/domain/ingress"
+	"github.com/skygeario/k8s-controller/pkg/domain/verification"
 )
 
 var scheme = runtime.NewScheme()
@@ -79,3 +82,80 @@ func (p *Provider) MakeIngress(reg *domainv1beta1.CustomDomainRegistration) (*ne
 
 	return &ingress, nil
 }
+
+func (p *Provider) MakeVerificationIngress(domain *domainv1beta1.CustomDomain, namespace string, backend networkingv1beta1.IngressBackend) (*networkingv1beta1.Ingress, error) {
+	ingress := networkingv1beta1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      domain.Name,
+			Namespace: namespace,
+			Annotations: map[string]string{
+				"kubernetes.io/ingress.class":              "nginx",
+				"nginx.ingress.kubernetes.io/ssl-redirect": "false",
+			},
+		},
+		Spec: networkingv1beta1.IngressSpec{
+			Rules: []networkingv1beta1.IngressRule{
+				networkingv1beta1.IngressRule{
+					Host: domain.Name,
+					IngressRuleValue: networkingv1beta1.IngressRuleValue{
+						HTTP: &networkingv1beta1.HTTPIngressRuleValue{
+							Paths: []networkingv1beta1.HTTPIngressPath{
+								networkingv1beta1.HTTPIngressPath{
+									Path:    verification.HTTPPathPrefix,
+									Backend: backend,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if err := ctrl.SetControllerReference(domain, &ingress, scheme); err != nil {
+		return nil, err
+	}
+
+	return &ingress, nil
+}
+
+func (p *Provider) MakeChallengeIngress(reg *domainv1beta1.CustomDomainRegistration, namespace string, path string, backend networkingv1beta1.IngressBackend) (*networkingv1beta1.Ingress, error) {
+	ingress := networkingv1beta1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			// Namespace and name joined by '-' may collide across
+			// registrations, so UID is used instead.
+			Name:      fmt.Sprintf("acme-%s", reg.UID),
+			Namespace: namespace,
+			Labels: map[string]string{
+				ingress.LabelRegistrationNamespace: reg.Namespace,
+				ingress.LabelRegistrationUID:       string(reg.UID),
+			},
+			Annotations: map[string]string{
+				"kubernetes.io/ingress.class":              "nginx",
+				"nginx.ingress.kubernetes.io/ssl-redirect": "false",
+			},
+		},
+		Spec: networkingv1beta1.IngressSpec{
+			Rules: []networkingv1beta1.IngressRule{
+				networkingv1beta1.IngressRule{
+					Host: reg.Spec.DomainName,
+					IngressRuleValue: networkingv1beta1.IngressRuleValue{
+						HTTP: &networkingv1beta1.HTTPIngressRuleValue{
+							Paths: []networkingv1beta1.HTTPIngressPath{
+								networkingv1beta1.HTTPIngressPath{
+									Path:    path,
+									Backend: backend,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// Ingress is in another namespace and cannot be owned by the registration;
+	// the ACME provider deletes it when the challenges are completed, and
+	// orphaned ones are identified by the labels.
+	return &ingress, nil
+}
@@ -5,6 +5,15 @@ import (
 	networkingv1beta1 "k8s.io/api/networking/v1beta1"
 )
 
+// Labels of challenge Ingresses, which are not owned by the registration and
+// are identified by these labels for garbage collection.
+const (
+	LabelRegistrationNamespace = "domain.skygear.io/registration-namespace"
+	LabelRegistrationUID       = "domain.skygear.io/registration-uid"
+)
+
 type Provider interface {
 	MakeIngress(reg *domainv1beta1.CustomDomainRegistration) (*networkingv1beta1.Ingress, error)
+	MakeVerificationIngress(domain *domainv1beta1.CustomDomain, namespace string, backend networkingv1beta1.IngressBackend) (*networkingv1beta1.Ingress, error)
+	MakeChallengeIngress(reg *domainv1beta1.CustomDomainRegistration, namespace string, path string, backend networkingv1beta1.IngressBackend) (*networkingv1beta1.Ingress, error)
 }
@@ -0,0 +1,146 @@
+package cdn
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const DefaultRequestTimeout = 10 * time.Second
+
+type DistributionStatus string
+
+const (
+	DistributionStatusPending  DistributionStatus = "pending"
+	DistributionStatusDeployed DistributionStatus = "deployed"
+	DistributionStatusDeleting DistributionStatus = "deleting"
+)
+
+// Distribution is a CDN distribution serving a domain.
+type Distribution struct {
+	ID     string             `json:"id"`
+	Domain string             `json:"domain"`
+	Origin string             `json:"origin"`
+	Status DistributionStatus `json:"status"`
+	// CNAME is the host name the domain should point to.
+	CNAME string `json:"cname"`
+}
+
+// Client is a client of CDN API.
+type Client interface {
+	// GetDistribution returns the distribution of domain, or nil if not exists.
+	GetDistribution(ctx context.Context, domain string) (*Distribution, error)
+	CreateDistribution(ctx context.Context, domain string, origin string) (*Distribution, error)
+	DeleteDistribution(ctx context.Context, id string) error
+}
+
+// HTTPClient is a Client of JSON HTTP CDN API, distributions are looked up
+// by domain at /distributions/{domain}, created by POST to /distributions,
+// and deleted by DELETE to /distributions/{id}.
+type HTTPClient struct {
+	Endpoint   string
+	Token      string
+	HTTPClient *http.Client
+}
+
+var _ Client = &HTTPClient{}
+
+func NewHTTPClient(config Config) (*HTTPClient, error) {
+	if config.APIEndpoint == "" {
+		return nil, fmt.Errorf("CDN API endpoint is missing")
+	}
+	if _, err := url.Parse(config.APIEndpoint); err != nil {
+		return nil, fmt.Errorf("CDN API endpoint is invalid: %w", err)
+	}
+
+	timeout := DefaultRequestTimeout
+	if config.Timeout != nil {
+		timeout = config.Timeout.Duration
+	}
+
+	return &HTTPClient{
+		Endpoint:   strings.TrimSuffix(config.APIEndpoint, "/"),
+		Token:      config.APIToken,
+		HTTPClient: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func (c *HTTPClient) GetDistribution(ctx context.Context, domain string) (*Distribution, error) {
+	var dist Distribution
+	status, err := c.do(ctx, "GET", "/distributions/"+url.PathEscape(domain), nil, &dist)
+	if status == http.StatusNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &dist, nil
+}
+
+func (c *HTTPClient) CreateDistribution(ctx context.Context, domain string, origin string) (*Distribution, error) {
+	var dist Distribution
+	body := &Distribution{Domain: domain, Origin: origin}
+	if _, err := c.do(ctx, "POST", "/distributions", body, &dist); err != nil {
+		return nil, err
+	}
+	return &dist, nil
+}
+
+func (c *HTTPClient) DeleteDistribution(ctx context.Context, id string) error {
+	status, err := c.do(ctx, "DELETE", "/distributions/"+url.PathEscape(id), nil, nil)
+	if status == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+func (c *HTTPClient) do(ctx context.Context, method string, path string, body interface{}, result interface{}) (int, error) {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.Endpoint+path, reqBody)
+	if err != nil {
+		return 0, err
+	}
+	req = req.WithContext(ctx)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("CDN API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, fmt.Errorf("CDN API request failed: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("CDN API request failed with status %d: %s",
+			resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	if result != nil {
+		if err := json.Unmarshal(respBody, result); err != nil {
+			return resp.StatusCode, fmt.Errorf("CDN API response is invalid: %w", err)
+		}
+	}
+	return resp.StatusCode, nil
+}
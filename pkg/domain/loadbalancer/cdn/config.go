@@ -0,0 +1,16 @@
+package cdn
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type Config struct {
+	// APIEndpoint is the base URL of CDN API.
+	APIEndpoint string
+	// APIToken is the bearer token used to authenticate with CDN API.
+	APIToken string
+	// Origin is the host name CDN distributions should fetch from.
+	Origin string
+	// Timeout is the timeout of each CDN API request.
+	Timeout *metav1.Duration
+}
@@ -0,0 +1,78 @@
+package cdn
+
+import (
+	"context"
+	"fmt"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer"
+)
+
+type Provider struct {
+	Client Client
+	Origin string
+}
+
+func NewProvider(config Config) (*Provider, error) {
+	if config.Origin == "" {
+		return nil, fmt.Errorf("CDN origin is missing")
+	}
+
+	client, err := NewHTTPClient(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Provider{
+		Client: client,
+		Origin: config.Origin,
+	}, nil
+}
+
+var _ loadbalancer.Provider = &Provider{}
+
+func (p *Provider) Provision(ctx context.Context, domain *domainv1beta1.CustomDomain) (*loadbalancer.ProvisionResult, error) {
+	if err := loadbalancer.CheckCNAMEAvailable(domain.Name); err != nil {
+		return nil, fmt.Errorf("CDN is unavailable: %w", err)
+	}
+
+	dist, err := p.Client.GetDistribution(ctx, domain.Name)
+	if err != nil {
+		return nil, err
+	}
+	if dist == nil {
+		dist, err = p.Client.CreateDistribution(ctx, domain.Name, p.Origin)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if dist.Status != DistributionStatusDeployed {
+		// Distribution is not yet deployed
+		return nil, nil
+	}
+
+	return &loadbalancer.ProvisionResult{
+		DNSRecords: []loadbalancer.DNSRecord{
+			{Name: domain.Name, Type: "CNAME", Value: dist.CNAME},
+		},
+	}, nil
+}
+
+func (p *Provider) Release(ctx context.Context, domain *domainv1beta1.CustomDomain) (bool, error) {
+	dist, err := p.Client.GetDistribution(ctx, domain.Name)
+	if err != nil {
+		return false, err
+	}
+	if dist == nil {
+		return true, nil
+	}
+
+	if dist.Status != DistributionStatusDeleting {
+		if err := p.Client.DeleteDistribution(ctx, dist.ID); err != nil {
+			return false, err
+		}
+	}
+	// Wait until distribution is deleted
+	return false, nil
+}
@@ -0,0 +1,90 @@
+package cdn_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	internaltest "github.com/skygeario/k8s-controller/internal/test"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer/cdn"
+)
+
+func TestProvisionAndRelease(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	server := internaltest.NewCDNServer()
+	defer server.Close()
+	server.Now = func() time.Time { return now }
+
+	provider, err := cdn.NewProvider(cdn.Config{
+		APIEndpoint: server.URL(),
+		APIToken:    server.Token,
+		Origin:      "origin.test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	domain := &domainv1beta1.CustomDomain{ObjectMeta: metav1.ObjectMeta{Name: "www.my-app.test"}}
+
+	result, err := provider.Provision(ctx, domain)
+	if err != nil || result != nil {
+		t.Fatalf("expected distribution to be pending: %v, %v", result, err)
+	}
+	dist := server.Distribution("www.my-app.test")
+	if dist == nil || dist.Origin != "origin.test" {
+		t.Fatalf("unexpected distribution: %v", dist)
+	}
+
+	now = now.Add(server.DeployTime)
+	result, err = provider.Provision(ctx, domain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := loadbalancer.DNSRecord{Name: "www.my-app.test", Type: "CNAME", Value: dist.CNAME}
+	if result == nil || len(result.DNSRecords) != 1 || result.DNSRecords[0] != expected {
+		t.Fatalf("unexpected result: %v", result)
+	}
+
+	if ok, err := provider.Release(ctx, domain); ok || err != nil {
+		t.Fatalf("expected distribution to be deleting: %v, %v", ok, err)
+	}
+	if ok, err := provider.Release(ctx, domain); ok || err != nil {
+		t.Fatalf("expected distribution to be deleting: %v, %v", ok, err)
+	}
+	now = now.Add(server.DeleteTime)
+	if ok, err := provider.Release(ctx, domain); !ok || err != nil {
+		t.Fatalf("expected distribution to be deleted: %v, %v", ok, err)
+	}
+	if dist := server.Distribution("www.my-app.test"); dist != nil {
+		t.Errorf("unexpected distribution: %v", dist)
+	}
+
+	root := &domainv1beta1.CustomDomain{ObjectMeta: metav1.ObjectMeta{Name: "my-app.test"}}
+	if _, err := provider.Provision(ctx, root); err == nil {
+		t.Errorf("expected root domain to be rejected")
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	server := internaltest.NewCDNServer()
+	defer server.Close()
+
+	provider, err := cdn.NewProvider(cdn.Config{
+		APIEndpoint: server.URL(),
+		APIToken:    "invalid-token",
+		Origin:      "origin.test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	domain := &domainv1beta1.CustomDomain{ObjectMeta: metav1.ObjectMeta{Name: "www.my-app.test"}}
+	_, err = provider.Provision(context.Background(), domain)
+	if err == nil || err.Error() != "CDN API request failed with status 401: unauthorized" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,20 @@
+package loadbalancer
+
+import (
+	"fmt"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// CheckCNAMEAvailable returns an error if the domain is at zone apex, where
+// CNAME record cannot co-exist with other records.
+func CheckCNAMEAvailable(domain string) error {
+	rootDomain, err := publicsuffix.EffectiveTLDPlusOne(domain)
+	if err != nil {
+		return err
+	}
+	if domain == rootDomain {
+		return fmt.Errorf("CNAME record is unavailable for root domain")
+	}
+	return nil
+}
@@ -0,0 +1,6 @@
+package cname
+
+type Config struct {
+	// Target is the host name CNAME records should point to.
+	Target string
+}
@@ -0,0 +1,42 @@
+package cname
+
+import (
+	"context"
+	"fmt"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer"
+)
+
+type Provider struct {
+	Target string
+}
+
+func NewProvider(config Config) (*Provider, error) {
+	if config.Target == "" {
+		return nil, fmt.Errorf("CNAME target is missing")
+	}
+
+	return &Provider{
+		Target: config.Target,
+	}, nil
+}
+
+var _ loadbalancer.Provider = &Provider{}
+
+func (p *Provider) Provision(ctx context.Context, domain *domainv1beta1.CustomDomain) (*loadbalancer.ProvisionResult, error) {
+	if err := loadbalancer.CheckCNAMEAvailable(domain.Name); err != nil {
+		return nil, err
+	}
+
+	return &loadbalancer.ProvisionResult{
+		DNSRecords: []loadbalancer.DNSRecord{
+			{Name: domain.Name, Type: "CNAME", Value: p.Target},
+		},
+	}, nil
+}
+
+func (p *Provider) Release(ctx context.Context, domain *domainv1beta1.CustomDomain) (bool, error) {
+	// Nothing to do.
+	return true, nil
+}
@@ -0,0 +1,45 @@
+package cname_test
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer/cname"
+)
+
+func TestProvisionAndRelease(t *testing.T) {
+	provider, err := cname.NewProvider(cname.Config{Target: "lb.example.test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	domain := &domainv1beta1.CustomDomain{ObjectMeta: metav1.ObjectMeta{Name: "www.my-app.test"}}
+
+	result, err := provider.Provision(ctx, domain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := loadbalancer.DNSRecord{Name: "www.my-app.test", Type: "CNAME", Value: "lb.example.test"}
+	if result == nil || len(result.DNSRecords) != 1 || result.DNSRecords[0] != expected {
+		t.Fatalf("unexpected result: %v", result)
+	}
+
+	if ok, err := provider.Release(ctx, domain); !ok || err != nil {
+		t.Fatalf("unexpected release result: %v, %v", ok, err)
+	}
+
+	root := &domainv1beta1.CustomDomain{ObjectMeta: metav1.ObjectMeta{Name: "my-app.test"}}
+	if _, err := provider.Provision(ctx, root); err == nil {
+		t.Errorf("expected root domain to be rejected")
+	}
+}
+
+func TestConfig(t *testing.T) {
+	if _, err := cname.NewProvider(cname.Config{}); err == nil {
+		t.Errorf("expected missing target to be rejected")
+	}
+}
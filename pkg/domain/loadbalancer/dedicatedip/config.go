@@ -0,0 +1,6 @@
+package dedicatedip
+
+type Config struct {
+	// IPAddresses is the pool of IP addresses to be allocated.
+	IPAddresses []string
+}
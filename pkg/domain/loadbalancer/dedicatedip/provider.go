@@ -0,0 +1,181 @@
+package dedicatedip
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net"
+
+	"golang.org/x/net/publicsuffix"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer"
+)
+
+// ReasonPoolExhausted is the condition reason when no IP address is available.
+const ReasonPoolExhausted = "IPPoolExhausted"
+
+type Provider struct {
+	KubeClient  client.Client
+	IPAddresses []net.IP
+}
+
+func NewProvider(client client.Client, config Config) (*Provider, error) {
+	ips := make([]net.IP, len(config.IPAddresses))
+	for i, s := range config.IPAddresses {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, fmt.Errorf("IP address '%s' is not valid", s)
+		}
+		ips[i] = ip
+	}
+
+	return &Provider{
+		KubeClient:  client,
+		IPAddresses: ips,
+	}, nil
+}
+
+var _ loadbalancer.Provider = &Provider{}
+
+func (p *Provider) Provision(ctx context.Context, domain *domainv1beta1.CustomDomain) (*loadbalancer.ProvisionResult, error) {
+	rootDomain, err := publicsuffix.EffectiveTLDPlusOne(domain.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	ip, err := p.allocate(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	var recordType string
+	if ip.To4() == nil {
+		recordType = "AAAA"
+	} else {
+		recordType = "A"
+	}
+
+	name := domain.Name
+	if name == rootDomain {
+		name = "@"
+	}
+
+	return &loadbalancer.ProvisionResult{
+		DNSRecords: []loadbalancer.DNSRecord{
+			{Name: name, Type: recordType, Value: ip.String()},
+		},
+	}, nil
+}
+
+func (p *Provider) Release(ctx context.Context, domain *domainv1beta1.CustomDomain) (bool, error) {
+	allocations, err := p.listAllocations(ctx, domain)
+	if err != nil {
+		return false, err
+	}
+
+	for _, alloc := range allocations {
+		err := p.KubeClient.Delete(ctx, &alloc)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (p *Provider) allocate(ctx context.Context, domain *domainv1beta1.CustomDomain) (net.IP, error) {
+	allocations, err := p.listAllocations(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	for _, alloc := range allocations {
+		if ip := net.ParseIP(alloc.Spec.Address); ip != nil {
+			return ip, nil
+		}
+	}
+
+	for _, ip := range p.IPAddresses {
+		// allocation name is derived from IP address, so that an address
+		// cannot be allocated twice
+		alloc := &domainv1beta1.IPAddressAllocation{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: allocationName(ip),
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion:         domainv1beta1.GroupVersion.String(),
+					Kind:               "CustomDomain",
+					Name:               domain.Name,
+					UID:                domain.UID,
+					BlockOwnerDeletion: pointer.BoolPtr(true),
+				}},
+			},
+			Spec: domainv1beta1.IPAddressAllocationSpec{
+				Address:      ip.String(),
+				CustomDomain: domain.Name,
+			},
+		}
+		err := p.KubeClient.Create(ctx, alloc)
+		if apierrors.IsAlreadyExists(err) {
+			// Listed allocations may be stale, the address may be already
+			// allocated to this domain.
+			var existing domainv1beta1.IPAddressAllocation
+			if err := p.KubeClient.Get(ctx, types.NamespacedName{Name: alloc.Name}, &existing); err != nil {
+				return nil, err
+			}
+			if existing.Spec.CustomDomain == domain.Name {
+				return ip, nil
+			}
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		return ip, nil
+	}
+
+	return nil, &loadbalancer.ProvisionError{
+		Reason: ReasonPoolExhausted,
+		Err:    fmt.Errorf("no IP address is available in the pool"),
+	}
+}
+
+// listAllocations lists allocations of the domain within the pool; the domain
+// may hold allocations of other pools while migrating between them.
+func (p *Provider) listAllocations(ctx context.Context, domain *domainv1beta1.CustomDomain) ([]domainv1beta1.IPAddressAllocation, error) {
+	var list domainv1beta1.IPAddressAllocationList
+	if err := p.KubeClient.List(ctx, &list); err != nil {
+		return nil, err
+	}
+
+	var allocations []domainv1beta1.IPAddressAllocation
+	for _, alloc := range list.Items {
+		if alloc.Spec.CustomDomain == domain.Name && p.inPool(alloc.Spec.Address) {
+			allocations = append(allocations, alloc)
+		}
+	}
+	return allocations, nil
+}
+
+func (p *Provider) inPool(address string) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+	for _, poolIP := range p.IPAddresses {
+		if poolIP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func allocationName(ip net.IP) string {
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4.String()
+	}
+	// IPv6 addresses are not valid object names
+	return hex.EncodeToString(ip.To16())
+}
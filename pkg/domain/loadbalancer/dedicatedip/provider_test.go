@@ -0,0 +1,163 @@
+package dedicatedip_test
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer/dedicatedip"
+)
+
+func newDomain(name string) *domainv1beta1.CustomDomain {
+	return &domainv1beta1.CustomDomain{ObjectMeta: metav1.ObjectMeta{Name: name}}
+}
+
+func TestProvisionAndRelease(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = domainv1beta1.AddToScheme(scheme)
+
+	provider, err := dedicatedip.NewProvider(fake.NewFakeClientWithScheme(scheme), dedicatedip.Config{
+		IPAddresses: []string{"192.0.2.1", "2001:db8::1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	provision := func(domain string) *loadbalancer.ProvisionResult {
+		result, err := provider.Provision(ctx, newDomain(domain))
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %s", domain, err)
+		}
+		return result
+	}
+
+	expected := loadbalancer.DNSRecord{Name: "@", Type: "A", Value: "192.0.2.1"}
+	if r := provision("my-app.test").DNSRecords; len(r) != 1 || r[0] != expected {
+		t.Errorf("unexpected records: %v", r)
+	}
+	if r := provision("my-app.test").DNSRecords; len(r) != 1 || r[0] != expected {
+		t.Errorf("expected allocation to be stable, got %v", r)
+	}
+
+	expected = loadbalancer.DNSRecord{Name: "www.other-app.test", Type: "AAAA", Value: "2001:db8::1"}
+	if r := provision("www.other-app.test").DNSRecords; len(r) != 1 || r[0] != expected {
+		t.Errorf("unexpected records: %v", r)
+	}
+
+	_, err = provider.Provision(ctx, newDomain("third-app.test"))
+	if reason := loadbalancer.ReasonOf(err); reason != dedicatedip.ReasonPoolExhausted {
+		t.Errorf("expected pool exhausted, got %v", err)
+	}
+
+	if ok, err := provider.Release(ctx, newDomain("my-app.test")); !ok || err != nil {
+		t.Fatalf("unexpected release result: %v, %v", ok, err)
+	}
+
+	expected = loadbalancer.DNSRecord{Name: "@", Type: "A", Value: "192.0.2.1"}
+	if r := provision("third-app.test").DNSRecords; len(r) != 1 || r[0] != expected {
+		t.Errorf("expected released address to be reused, got %v", r)
+	}
+}
+
+// staleListClient lists no objects, like a cache not yet synced.
+type staleListClient struct {
+	client.Client
+}
+
+func (c staleListClient) List(ctx context.Context, list runtime.Object, opts ...client.ListOption) error {
+	return nil
+}
+
+func TestProvisionWithStaleList(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = domainv1beta1.AddToScheme(scheme)
+
+	allocation := func(address, domain, name string) *domainv1beta1.IPAddressAllocation {
+		return &domainv1beta1.IPAddressAllocation{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       domainv1beta1.IPAddressAllocationSpec{Address: address, CustomDomain: domain},
+		}
+	}
+	kubeClient := fake.NewFakeClientWithScheme(scheme,
+		allocation("192.0.2.1", "other-app.test", "192.0.2.1"),
+		allocation("192.0.2.2", "my-app.test", "192.0.2.2"),
+	)
+	provider, err := dedicatedip.NewProvider(staleListClient{kubeClient}, dedicatedip.Config{
+		IPAddresses: []string{"192.0.2.1", "192.0.2.2", "192.0.2.3"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := provider.Provision(context.Background(), newDomain("my-app.test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := loadbalancer.DNSRecord{Name: "@", Type: "A", Value: "192.0.2.2"}
+	if r := result.DNSRecords; len(r) != 1 || r[0] != expected {
+		t.Errorf("expected existing allocation to be used, got %v", r)
+	}
+
+	var list domainv1beta1.IPAddressAllocationList
+	if err := kubeClient.List(context.Background(), &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Items) != 2 {
+		t.Errorf("unexpected allocations: %v", list.Items)
+	}
+}
+
+func TestMigrateBetweenPools(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = domainv1beta1.AddToScheme(scheme)
+	kubeClient := fake.NewFakeClientWithScheme(scheme)
+
+	newProvider := func(addresses ...string) *dedicatedip.Provider {
+		provider, err := dedicatedip.NewProvider(kubeClient, dedicatedip.Config{IPAddresses: addresses})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return provider
+	}
+	source := newProvider("192.0.2.1")
+	target := newProvider("198.51.100.1")
+	ctx := context.Background()
+	domain := newDomain("my-app.test")
+
+	address := func(provider *dedicatedip.Provider) string {
+		result, err := provider.Provision(ctx, domain)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result.DNSRecords[0].Value
+	}
+
+	if a := address(source); a != "192.0.2.1" {
+		t.Errorf("unexpected source address: %s", a)
+	}
+	if a := address(target); a != "198.51.100.1" {
+		t.Errorf("expected address from target pool, got %s", a)
+	}
+
+	// releasing source keeps allocation of target
+	if ok, err := source.Release(ctx, domain); !ok || err != nil {
+		t.Fatalf("unexpected release result: %v, %v", ok, err)
+	}
+	var list domainv1beta1.IPAddressAllocationList
+	if err := kubeClient.List(ctx, &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Items) != 1 || list.Items[0].Spec.Address != "198.51.100.1" {
+		t.Errorf("unexpected allocations: %#v", list.Items)
+	}
+	if a := address(target); a != "198.51.100.1" {
+		t.Errorf("expected target allocation to be stable, got %s", a)
+	}
+}
@@ -0,0 +1,12 @@
+package plugin
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type Config struct {
+	// Endpoint is the base URL of the plugin, e.g. http://localhost:9000.
+	Endpoint string
+	// Timeout is the timeout of each plugin request.
+	Timeout *metav1.Duration
+}
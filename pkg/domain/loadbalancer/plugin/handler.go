@@ -0,0 +1,115 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer"
+)
+
+// NewHandler serves a loadbalancer.Provider using the plugin protocol.
+func NewHandler(provider loadbalancer.Provider) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(ProvisionPath, func(rw http.ResponseWriter, r *http.Request) {
+		var req ProvisionRequest
+		if !decodeRequest(rw, r, &req) || !validateRequest(rw, req.Version, req.Domain) {
+			return
+		}
+
+		result, err := provider.Provision(r.Context(), makeCustomDomain(req.Domain))
+		if err != nil {
+			writeError(rw, err)
+			return
+		}
+
+		resp := ProvisionResponse{Provisioned: result != nil}
+		if result != nil && result.Region != nil {
+			resp.Region = *result.Region
+		}
+		if result != nil {
+			resp.DNSRecords = make([]DNSRecord, len(result.DNSRecords))
+			for i, r := range result.DNSRecords {
+				resp.DNSRecords[i] = DNSRecord{Name: r.Name, Type: r.Type, Value: r.Value}
+			}
+		}
+		writeJSON(rw, http.StatusOK, resp)
+	})
+	mux.HandleFunc(ReleasePath, func(rw http.ResponseWriter, r *http.Request) {
+		var req ReleaseRequest
+		if !decodeRequest(rw, r, &req) || !validateRequest(rw, req.Version, req.Domain) {
+			return
+		}
+
+		released, err := provider.Release(r.Context(), makeCustomDomain(req.Domain))
+		if err != nil {
+			writeError(rw, err)
+			return
+		}
+		writeJSON(rw, http.StatusOK, ReleaseResponse{Released: released})
+	})
+	return mux
+}
+
+func decodeRequest(rw http.ResponseWriter, r *http.Request, req interface{}) bool {
+	if r.Method != "POST" {
+		writeJSON(rw, http.StatusMethodNotAllowed, ErrorResponse{Message: "method not allowed"})
+		return false
+	}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeJSON(rw, http.StatusBadRequest, ErrorResponse{Message: err.Error()})
+		return false
+	}
+	return true
+}
+
+func validateRequest(rw http.ResponseWriter, version string, domain Domain) bool {
+	if version != ProtocolVersion {
+		writeJSON(rw, http.StatusBadRequest, ErrorResponse{Message: fmt.Sprintf("protocol version '%s' is not supported", version)})
+		return false
+	}
+	if domain.Name == "" {
+		writeJSON(rw, http.StatusBadRequest, ErrorResponse{Message: "domain is missing"})
+		return false
+	}
+	return true
+}
+
+func makeCustomDomain(domain Domain) *domainv1beta1.CustomDomain {
+	d := &domainv1beta1.CustomDomain{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: domain.Name,
+			UID:  types.UID(domain.UID),
+		},
+		Spec: domainv1beta1.CustomDomainSpec{
+			LoadBalancerProvider: &domain.Provider,
+		},
+	}
+	if domain.Region != "" {
+		d.Spec.Region = &domain.Region
+	}
+	return d
+}
+
+func writeError(rw http.ResponseWriter, err error) {
+	reason := loadbalancer.ReasonOf(err)
+	status := http.StatusInternalServerError
+	if reason != "" {
+		// errors with reason are not transient
+		status = http.StatusUnprocessableEntity
+	}
+	writeJSON(rw, status, ErrorResponse{
+		Reason:  reason,
+		Message: err.Error(),
+	})
+}
+
+func writeJSON(rw http.ResponseWriter, status int, body interface{}) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	_ = json.NewEncoder(rw).Encode(body)
+}
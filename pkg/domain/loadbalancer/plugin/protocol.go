@@ -0,0 +1,62 @@
+package plugin
+
+// Plugins implement the following HTTP/JSON protocol:
+// POST /provision with ProvisionRequest, responds with ProvisionResponse;
+// POST /release with ReleaseRequest, responds with ReleaseResponse.
+// Failures are responded with non-2xx status and ErrorResponse, and the
+// request is retried when the domain is reconciled again. Malformed requests
+// are responded with status 400.
+const (
+	ProvisionPath = "/provision"
+	ReleasePath   = "/release"
+)
+
+// ProtocolVersion is the version of the protocol sent in requests; plugins
+// reject requests of unsupported versions with status 400.
+const ProtocolVersion = "v1"
+
+// Domain is the custom domain served by the plugin.
+type Domain struct {
+	Name string `json:"name"`
+	UID  string `json:"uid"`
+	// Region is the requested home region of the domain, empty if unspecified.
+	Region string `json:"region,omitempty"`
+	// Provider is the name of the plugin load balancer provider.
+	Provider string `json:"provider"`
+}
+
+type DNSRecord struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type ProvisionRequest struct {
+	Version string `json:"version"`
+	Domain  Domain `json:"domain"`
+}
+
+type ProvisionResponse struct {
+	// Provisioned indicates whether the load balancer is provisioned.
+	Provisioned bool        `json:"provisioned"`
+	DNSRecords  []DNSRecord `json:"dnsRecords,omitempty"`
+	// Region is the region served by the load balancer, empty if the plugin
+	// is not region-aware.
+	Region string `json:"region,omitempty"`
+}
+
+type ReleaseRequest struct {
+	Version string `json:"version"`
+	Domain  Domain `json:"domain"`
+}
+
+type ReleaseResponse struct {
+	// Released indicates whether the load balancer is released.
+	Released bool `json:"released"`
+}
+
+type ErrorResponse struct {
+	// Reason is a CamelCase reason reported in condition.
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,149 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer"
+)
+
+const DefaultRequestTimeout = 10 * time.Second
+
+// ReasonPluginUnavailable is the condition reason when plugin cannot be reached.
+const ReasonPluginUnavailable = "PluginUnavailable"
+
+type Provider struct {
+	Name       string
+	Endpoint   string
+	HTTPClient *http.Client
+}
+
+func NewProvider(name string, config Config) (*Provider, error) {
+	if config.Endpoint == "" {
+		return nil, fmt.Errorf("plugin endpoint is missing")
+	}
+	if _, err := url.Parse(config.Endpoint); err != nil {
+		return nil, fmt.Errorf("plugin endpoint is invalid: %w", err)
+	}
+
+	timeout := DefaultRequestTimeout
+	if config.Timeout != nil {
+		timeout = config.Timeout.Duration
+	}
+	return &Provider{
+		Name:       name,
+		Endpoint:   strings.TrimSuffix(config.Endpoint, "/"),
+		HTTPClient: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+var _ loadbalancer.Provider = &Provider{}
+
+func (p *Provider) Provision(ctx context.Context, domain *domainv1beta1.CustomDomain) (*loadbalancer.ProvisionResult, error) {
+	req := &ProvisionRequest{Version: ProtocolVersion, Domain: p.makeDomain(domain)}
+
+	var resp ProvisionResponse
+	if err := p.call(ctx, ProvisionPath, req, &resp); err != nil {
+		return nil, err
+	}
+	if !resp.Provisioned {
+		return nil, nil
+	}
+
+	dnsRecords := make([]loadbalancer.DNSRecord, len(resp.DNSRecords))
+	for i, r := range resp.DNSRecords {
+		dnsRecords[i] = loadbalancer.DNSRecord{Name: r.Name, Type: r.Type, Value: r.Value}
+	}
+	result := &loadbalancer.ProvisionResult{
+		DNSRecords: dnsRecords,
+	}
+	if resp.Region != "" {
+		result.Region = &resp.Region
+	}
+	return result, nil
+}
+
+func (p *Provider) Release(ctx context.Context, domain *domainv1beta1.CustomDomain) (bool, error) {
+	var resp ReleaseResponse
+	req := &ReleaseRequest{Version: ProtocolVersion, Domain: p.makeDomain(domain)}
+	if err := p.call(ctx, ReleasePath, req, &resp); err != nil {
+		return false, err
+	}
+	return resp.Released, nil
+}
+
+// makeDomain makes the domain sent to the plugin, which excludes the
+// verification keys and other internal states of the domain.
+func (p *Provider) makeDomain(domain *domainv1beta1.CustomDomain) Domain {
+	d := Domain{
+		Name:     domain.Name,
+		UID:      string(domain.UID),
+		Provider: p.Name,
+	}
+	if domain.Spec.Region != nil {
+		d.Region = *domain.Spec.Region
+	}
+	return d
+}
+
+// call sends a request to the plugin. Failed requests are not retried here,
+// so that reconciliation is not blocked; they are retried when the domain is
+// requeued.
+func (p *Provider) call(ctx context.Context, path string, body interface{}, result interface{}) error {
+	reqBody, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", p.Endpoint+path, bytes.NewReader(reqBody))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.HTTPClient.Do(req)
+	if err != nil {
+		return &loadbalancer.ProvisionError{
+			Reason: ReasonPluginUnavailable,
+			Err:    fmt.Errorf("load-balancer plugin '%s' request failed: %w", p.Name, err),
+		}
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return &loadbalancer.ProvisionError{
+			Reason: ReasonPluginUnavailable,
+			Err:    fmt.Errorf("load-balancer plugin '%s' request failed: %w", p.Name, err),
+		}
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var errResp ErrorResponse
+		if err := json.Unmarshal(respBody, &errResp); err != nil || errResp.Message == "" {
+			errResp.Message = strings.TrimSpace(string(respBody))
+		}
+		reason := errResp.Reason
+		if reason == "" && resp.StatusCode >= 500 {
+			reason = ReasonPluginUnavailable
+		}
+		return &loadbalancer.ProvisionError{
+			Reason: reason,
+			Err:    fmt.Errorf("load-balancer plugin '%s' failed: %s", p.Name, errResp.Message),
+		}
+	}
+
+	if err := json.Unmarshal(respBody, result); err != nil {
+		return fmt.Errorf("load-balancer plugin '%s' response is invalid: %w", p.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package plugin_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer/plugin"
+)
+
+type stubProvider struct {
+	provisioned bool
+	err         error
+}
+
+func (p *stubProvider) Provision(ctx context.Context, domain *domainv1beta1.CustomDomain) (*loadbalancer.ProvisionResult, error) {
+	if p.err != nil || !p.provisioned {
+		return nil, p.err
+	}
+	return &loadbalancer.ProvisionResult{
+		DNSRecords: []loadbalancer.DNSRecord{
+			{Name: domain.Name, Type: "A", Value: "192.0.2.1"},
+		},
+		Region: domain.Spec.Region,
+	}, nil
+}
+
+func (p *stubProvider) Release(ctx context.Context, domain *domainv1beta1.CustomDomain) (bool, error) {
+	return p.provisioned, p.err
+}
+
+func newProvider(t *testing.T, endpoint string) *plugin.Provider {
+	provider, err := plugin.NewProvider("stub", plugin.Config{
+		Endpoint: endpoint,
+		Timeout:  &metav1.Duration{Duration: 100 * time.Millisecond},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return provider
+}
+
+func TestProvisionAndRelease(t *testing.T) {
+	stub := &stubProvider{}
+	server := httptest.NewServer(plugin.NewHandler(stub))
+	defer server.Close()
+
+	provider := newProvider(t, server.URL)
+	ctx := context.Background()
+	domain := &domainv1beta1.CustomDomain{ObjectMeta: metav1.ObjectMeta{Name: "my-app.test"}}
+
+	if result, err := provider.Provision(ctx, domain); result != nil || err != nil {
+		t.Errorf("expected pending result: %v, %v", result, err)
+	}
+	if ok, err := provider.Release(ctx, domain); ok || err != nil {
+		t.Errorf("expected pending release: %v, %v", ok, err)
+	}
+
+	stub.provisioned = true
+	result, err := provider.Provision(ctx, domain)
+	expected := loadbalancer.DNSRecord{Name: "my-app.test", Type: "A", Value: "192.0.2.1"}
+	if err != nil || result == nil || len(result.DNSRecords) != 1 || result.DNSRecords[0] != expected {
+		t.Errorf("unexpected result: %v, %v", result, err)
+	}
+	if ok, err := provider.Release(ctx, domain); !ok || err != nil {
+		t.Errorf("unexpected release result: %v, %v", ok, err)
+	}
+
+	stub.err = &loadbalancer.ProvisionError{Reason: "QuotaExceeded", Err: fmt.Errorf("quota exceeded")}
+	_, err = provider.Provision(ctx, domain)
+	if loadbalancer.ReasonOf(err) != "QuotaExceeded" || err.Error() != "load-balancer plugin 'stub' failed: quota exceeded" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestServerError(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		attempts++
+		http.Error(rw, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	provider := newProvider(t, server.URL)
+	domain := &domainv1beta1.CustomDomain{ObjectMeta: metav1.ObjectMeta{Name: "my-app.test"}}
+
+	// failed requests are retried by the controller instead
+	_, err := provider.Provision(context.Background(), domain)
+	if loadbalancer.ReasonOf(err) != plugin.ReasonPluginUnavailable || attempts != 1 {
+		t.Errorf("unexpected error after %d attempts: %v", attempts, err)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	provider := newProvider(t, server.URL)
+	domain := &domainv1beta1.CustomDomain{ObjectMeta: metav1.ObjectMeta{Name: "my-app.test"}}
+
+	_, err := provider.Provision(context.Background(), domain)
+	if loadbalancer.ReasonOf(err) != plugin.ReasonPluginUnavailable {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestRegion(t *testing.T) {
+	server := httptest.NewServer(plugin.NewHandler(&stubProvider{provisioned: true}))
+	defer server.Close()
+
+	provider := newProvider(t, server.URL)
+	ctx := context.Background()
+	domain := &domainv1beta1.CustomDomain{ObjectMeta: metav1.ObjectMeta{Name: "my-app.test"}}
+
+	if result, err := provider.Provision(ctx, domain); err != nil || result == nil || result.Region != nil {
+		t.Errorf("unexpected result: %v, %v", result, err)
+	}
+
+	domain.Spec.Region = pointer.StringPtr("eu-west")
+	result, err := provider.Provision(ctx, domain)
+	if err != nil || result == nil || result.Region == nil || *result.Region != "eu-west" {
+		t.Errorf("unexpected result: %v, %v", result, err)
+	}
+}
+
+func TestMalformedRequest(t *testing.T) {
+	server := httptest.NewServer(plugin.NewHandler(&stubProvider{provisioned: true}))
+	defer server.Close()
+
+	for _, path := range []string{plugin.ProvisionPath, plugin.ReleasePath} {
+		for _, body := range []string{
+			"",
+			"{}",
+			`{"version":"v1","domain":null}`,
+			`{"version":"v0","domain":{"name":"my-app.test"}}`,
+			"not json",
+		} {
+			resp, err := http.Post(server.URL+path, "application/json", strings.NewReader(body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("unexpected status for %s with body %q: %d", path, body, resp.StatusCode)
+			}
+		}
+	}
+}
+
+func TestRequestDomain(t *testing.T) {
+	var req plugin.ProvisionRequest
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Error(err)
+		}
+		if strings.Contains(string(body), "verification-key") {
+			t.Errorf("verification key is sent to plugin: %s", body)
+		}
+		_ = json.NewEncoder(rw).Encode(plugin.ProvisionResponse{})
+	}))
+	defer server.Close()
+
+	provider := newProvider(t, server.URL)
+	domain := &domainv1beta1.CustomDomain{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-app.test", UID: "my-app-uid"},
+		Spec: domainv1beta1.CustomDomainSpec{
+			VerificationKey: pointer.StringPtr("verification-key"),
+			Region:          pointer.StringPtr("eu-west"),
+		},
+	}
+	if _, err := provider.Provision(context.Background(), domain); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := plugin.Domain{Name: "my-app.test", UID: "my-app-uid", Region: "eu-west", Provider: "stub"}
+	if req.Version != plugin.ProtocolVersion || req.Domain != expected {
+		t.Errorf("unexpected request: %#v", req)
+	}
+}
@@ -2,6 +2,7 @@ package loadbalancer
 
 import (
 	"context"
+	"errors"
 
 	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
 )
@@ -13,6 +14,9 @@ type Provider interface {
 
 type ProvisionResult struct {
 	DNSRecords []DNSRecord
+	// Region is the region served by the DNS records, nil if the provider is
+	// not region-aware.
+	Region *string
 }
 
 type DNSRecord struct {
@@ -20,3 +24,27 @@ type DNSRecord struct {
 	Type  string
 	Value string
 }
+
+// ProvisionError is an error with reason to be reported in load balancer
+// condition.
+type ProvisionError struct {
+	Reason string
+	Err    error
+}
+
+func (e *ProvisionError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ProvisionError) Unwrap() error {
+	return e.Err
+}
+
+// ReasonOf returns the condition reason of err, or empty if not available.
+func ReasonOf(err error) string {
+	var perr *ProvisionError
+	if errors.As(err, &perr) {
+		return perr.Reason
+	}
+	return ""
+}
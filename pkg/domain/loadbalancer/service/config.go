@@ -0,0 +1,8 @@
+package service
+
+type Config struct {
+	// Namespace is the namespace of the LoadBalancer Service.
+	Namespace string
+	// Name is the name of the LoadBalancer Service.
+	Name string
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"golang.org/x/net/publicsuffix"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer"
+)
+
+type Provider struct {
+	KubeClient client.Client
+	Service    types.NamespacedName
+}
+
+func NewProvider(client client.Client, config Config) (*Provider, error) {
+	if config.Namespace == "" || config.Name == "" {
+		return nil, fmt.Errorf("load balancer Service is missing")
+	}
+
+	return &Provider{
+		KubeClient: client,
+		Service:    types.NamespacedName{Namespace: config.Namespace, Name: config.Name},
+	}, nil
+}
+
+var _ loadbalancer.Provider = &Provider{}
+
+func (p *Provider) Provision(ctx context.Context, domain *domainv1beta1.CustomDomain) (*loadbalancer.ProvisionResult, error) {
+	rootDomain, err := publicsuffix.EffectiveTLDPlusOne(domain.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	var svc corev1.Service
+	if err := p.KubeClient.Get(ctx, p.Service, &svc); err != nil {
+		return nil, err
+	}
+	if svc.Spec.Type != corev1.ServiceTypeLoadBalancer {
+		return nil, fmt.Errorf("load balancer Service '%s' is not of type LoadBalancer", p.Service)
+	}
+
+	name := domain.Name
+	if name == rootDomain {
+		name = "@"
+	}
+
+	var dnsRecords []loadbalancer.DNSRecord
+	var hostnames []string
+	for _, ingress := range svc.Status.LoadBalancer.Ingress {
+		if ingress.IP != "" {
+			ip := net.ParseIP(ingress.IP)
+			if ip == nil {
+				return nil, fmt.Errorf("IP address '%s' is not valid", ingress.IP)
+			}
+
+			var recordType string
+			if ip.To4() == nil {
+				recordType = "AAAA"
+			} else {
+				recordType = "A"
+			}
+			dnsRecords = append(dnsRecords, loadbalancer.DNSRecord{
+				Name:  name,
+				Type:  recordType,
+				Value: ip.String(),
+			})
+		} else if ingress.Hostname != "" {
+			hostnames = append(hostnames, ingress.Hostname)
+		}
+	}
+
+	if len(dnsRecords) == 0 && len(hostnames) > 0 {
+		if err := loadbalancer.CheckCNAMEAvailable(domain.Name); err != nil {
+			return nil, fmt.Errorf("load balancer host name is unavailable: %w", err)
+		}
+		// only one CNAME record is allowed for a name
+		dnsRecords = append(dnsRecords, loadbalancer.DNSRecord{
+			Name:  name,
+			Type:  "CNAME",
+			Value: hostnames[0],
+		})
+	}
+
+	if len(dnsRecords) == 0 {
+		// Service is not yet assigned an address
+		return nil, nil
+	}
+
+	return &loadbalancer.ProvisionResult{
+		DNSRecords: dnsRecords,
+	}, nil
+}
+
+func (p *Provider) Release(ctx context.Context, domain *domainv1beta1.CustomDomain) (bool, error) {
+	// Nothing to do.
+	return true, nil
+}
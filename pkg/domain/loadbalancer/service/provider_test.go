@@ -0,0 +1,97 @@
+package service_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer/service"
+)
+
+func newDomain(name string) *domainv1beta1.CustomDomain {
+	return &domainv1beta1.CustomDomain{ObjectMeta: metav1.ObjectMeta{Name: name}}
+}
+
+func TestProvisionAndRelease(t *testing.T) {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ingress", Name: "lb"},
+		Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer},
+	}
+	kubeClient := fake.NewFakeClientWithScheme(clientgoscheme.Scheme, svc)
+	provider, err := service.NewProvider(kubeClient, service.Config{Namespace: "ingress", Name: "lb"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	setIngress := func(ingress ...corev1.LoadBalancerIngress) {
+		svc.Status.LoadBalancer.Ingress = ingress
+		if err := kubeClient.Update(ctx, svc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	provision := func(domain string) []loadbalancer.DNSRecord {
+		result, err := provider.Provision(ctx, newDomain(domain))
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %s", domain, err)
+		}
+		if result == nil {
+			return nil
+		}
+		return result.DNSRecords
+	}
+
+	// Service is pending address assignment
+	if r := provision("my-app.test"); r != nil {
+		t.Errorf("expected provision to be pending, got %v", r)
+	}
+
+	setIngress(corev1.LoadBalancerIngress{IP: "192.0.2.1"}, corev1.LoadBalancerIngress{IP: "2001:db8::1"})
+	expected := []loadbalancer.DNSRecord{
+		{Name: "@", Type: "A", Value: "192.0.2.1"},
+		{Name: "@", Type: "AAAA", Value: "2001:db8::1"},
+	}
+	if r := provision("my-app.test"); !reflect.DeepEqual(r, expected) {
+		t.Errorf("unexpected records: %v", r)
+	}
+
+	setIngress(corev1.LoadBalancerIngress{Hostname: "lb.example.test"})
+	expected = []loadbalancer.DNSRecord{
+		{Name: "www.my-app.test", Type: "CNAME", Value: "lb.example.test"},
+	}
+	if r := provision("www.my-app.test"); !reflect.DeepEqual(r, expected) {
+		t.Errorf("unexpected records: %v", r)
+	}
+	if _, err := provider.Provision(ctx, newDomain("my-app.test")); err == nil {
+		t.Errorf("expected host name to be rejected for root domain")
+	}
+
+	if ok, err := provider.Release(ctx, newDomain("my-app.test")); !ok || err != nil {
+		t.Fatalf("unexpected release result: %v, %v", ok, err)
+	}
+}
+
+func TestServiceType(t *testing.T) {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ingress", Name: "lb"},
+		Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP},
+	}
+	provider, err := service.NewProvider(fake.NewFakeClientWithScheme(clientgoscheme.Scheme, svc), service.Config{Namespace: "ingress", Name: "lb"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := provider.Provision(context.Background(), newDomain("my-app.test")); err == nil {
+		t.Errorf("expected non-LoadBalancer Service to be rejected")
+	}
+
+	if _, err := service.NewProvider(nil, service.Config{Name: "lb"}); err == nil {
+		t.Errorf("expected missing Service namespace to be rejected")
+	}
+}
@@ -1,5 +1,31 @@
 package staticip
 
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
 type Config struct {
+	// IPAddresses are the IP addresses used when no regions are configured.
 	IPAddresses []string
+	// Regions are the IP address sets of each region.
+	Regions map[string][]string
+	// DefaultRegion is the region used by domains without home region.
+	DefaultRegion string
+	// HealthCheck enables health checks of IP addresses if specified.
+	HealthCheck *HealthCheckConfig
+}
+
+type HealthCheckConfig struct {
+	// Protocol is the protocol of health check, either tcp or http.
+	Protocol string
+	// Port is the port to be checked.
+	Port int
+	// Path is the HTTP path to be checked, defaults to /healthz.
+	Path string
+	// Host is the HTTP host header, defaults to the IP address.
+	Host string
+	// Interval is the interval between health checks, defaults to 10s.
+	Interval *metav1.Duration
+	// Timeout is the timeout of each health check, defaults to 3s.
+	Timeout *metav1.Duration
 }
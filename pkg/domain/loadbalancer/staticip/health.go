@@ -0,0 +1,210 @@
+package staticip
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	HealthCheckTCP  = "tcp"
+	HealthCheckHTTP = "http"
+
+	defaultHealthCheckPath     = "/healthz"
+	defaultHealthCheckInterval = 10 * time.Second
+	defaultHealthCheckTimeout  = 3 * time.Second
+)
+
+var addressHealthy = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "static_ip_address_healthy",
+		Help: "Whether the static IP address passes health check",
+	},
+	[]string{"address"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(addressHealthy)
+}
+
+// HealthChecker checks health of IP addresses. Results are refreshed on
+// demand when stale, and periodically once the checker is started.
+type HealthChecker struct {
+	Protocol string
+	Port     int
+	Path     string
+	Host     string
+	Interval time.Duration
+	Timeout  time.Duration
+	// OnChange is called when health of any address is changed.
+	OnChange func()
+
+	ips       []net.IP
+	client    *http.Client
+	lock      sync.Mutex
+	healthy   map[string]bool
+	checkedAt time.Time
+}
+
+func NewHealthChecker(config HealthCheckConfig, ips []net.IP) (*HealthChecker, error) {
+	switch config.Protocol {
+	case HealthCheckTCP, HealthCheckHTTP:
+		break
+	default:
+		return nil, fmt.Errorf("invalid health check protocol: '%s'", config.Protocol)
+	}
+	if config.Port <= 0 || config.Port > 65535 {
+		return nil, fmt.Errorf("invalid health check port: %d", config.Port)
+	}
+
+	c := &HealthChecker{
+		Protocol: config.Protocol,
+		Port:     config.Port,
+		Path:     config.Path,
+		Host:     config.Host,
+		Interval: defaultHealthCheckInterval,
+		Timeout:  defaultHealthCheckTimeout,
+		ips:      ips,
+		healthy:  map[string]bool{},
+	}
+	if c.Path == "" {
+		c.Path = defaultHealthCheckPath
+	}
+	if config.Interval != nil {
+		c.Interval = config.Interval.Duration
+	}
+	if config.Timeout != nil {
+		c.Timeout = config.Timeout.Duration
+	}
+	c.client = &http.Client{
+		Timeout: c.Timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return c, nil
+}
+
+// Start checks health periodically until stop is closed.
+func (c *HealthChecker) Start(stop <-chan struct{}) error {
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stop
+		cancel()
+	}()
+
+	for {
+		c.Check(ctx)
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Healthy returns the healthy IP addresses in ips. All addresses are
+// returned if none of them is healthy, so that the domain is still served
+// when health check is misconfigured.
+func (c *HealthChecker) Healthy(ctx context.Context, ips []net.IP) []net.IP {
+	c.lock.Lock()
+	stale := time.Since(c.checkedAt) >= c.Interval
+	c.lock.Unlock()
+	if stale {
+		c.Check(ctx)
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	var healthy []net.IP
+	for _, ip := range ips {
+		if c.healthy[ip.String()] {
+			healthy = append(healthy, ip)
+		}
+	}
+	if len(healthy) == 0 {
+		return ips
+	}
+	return healthy
+}
+
+// Check checks health of all IP addresses.
+func (c *HealthChecker) Check(ctx context.Context) {
+	results := make([]bool, len(c.ips))
+	var wg sync.WaitGroup
+	for i, ip := range c.ips {
+		wg.Add(1)
+		go func(i int, ip net.IP) {
+			defer wg.Done()
+			results[i] = c.probe(ctx, ip) == nil
+		}(i, ip)
+	}
+	wg.Wait()
+
+	c.lock.Lock()
+	changed := false
+	for i, ip := range c.ips {
+		addr := ip.String()
+		if healthy, ok := c.healthy[addr]; ok && healthy != results[i] {
+			changed = true
+		}
+		c.healthy[addr] = results[i]
+		if results[i] {
+			addressHealthy.WithLabelValues(addr).Set(1)
+		} else {
+			addressHealthy.WithLabelValues(addr).Set(0)
+		}
+	}
+	c.checkedAt = time.Now()
+	c.lock.Unlock()
+
+	if changed && c.OnChange != nil {
+		c.OnChange()
+	}
+}
+
+func (c *HealthChecker) probe(ctx context.Context, ip net.IP) error {
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	addr := net.JoinHostPort(ip.String(), strconv.Itoa(c.Port))
+	switch c.Protocol {
+	case HealthCheckTCP:
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+
+	case HealthCheckHTTP:
+		req, err := http.NewRequest("GET", "http://"+addr+c.Path, nil)
+		if err != nil {
+			return err
+		}
+		if c.Host != "" {
+			req.Host = c.Host
+		}
+		resp, err := c.client.Do(req.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+			return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		}
+		return nil
+	}
+	return fmt.Errorf("invalid health check protocol: '%s'", c.Protocol)
+}
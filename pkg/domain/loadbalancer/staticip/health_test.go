@@ -0,0 +1,106 @@
+package staticip_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer/staticip"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestProvisionWithHealthCheck(t *testing.T) {
+	healthy := true
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthz" || !healthy {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	_, portStr, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, _ := strconv.Atoi(portStr)
+
+	provider, err := staticip.NewProvider(staticip.Config{
+		// only 127.0.0.1 is served by the test server
+		IPAddresses: []string{"127.0.0.1", "127.0.0.2"},
+		HealthCheck: &staticip.HealthCheckConfig{
+			Protocol: staticip.HealthCheckHTTP,
+			Port:     port,
+			Interval: &metav1.Duration{Duration: time.Hour},
+			Timeout:  &metav1.Duration{Duration: time.Second},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed := false
+	provider.HealthChecker.OnChange = func() { changed = true }
+
+	ctx := context.Background()
+	domain := &domainv1beta1.CustomDomain{ObjectMeta: metav1.ObjectMeta{Name: "my-app.test"}}
+	result, err := provider.Provision(ctx, domain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.DNSRecords) != 1 || result.DNSRecords[0].Value != "127.0.0.1" {
+		t.Errorf("expected unhealthy address to be dropped, got %v", result.DNSRecords)
+	}
+
+	// all addresses are returned if none of them is healthy
+	healthy = false
+	provider.HealthChecker.Check(ctx)
+	if !changed {
+		t.Errorf("expected health change to be notified")
+	}
+	result, err = provider.Provision(ctx, domain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.DNSRecords) != 2 {
+		t.Errorf("expected all addresses to be returned, got %v", result.DNSRecords)
+	}
+}
+
+func TestHealthCheckTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	checker, err := staticip.NewHealthChecker(staticip.HealthCheckConfig{
+		Protocol: staticip.HealthCheckTCP,
+		Port:     port,
+		Interval: &metav1.Duration{Duration: time.Hour},
+	}, []net.IP{net.ParseIP("127.0.0.1"), net.ParseIP("127.0.0.2")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ips := checker.Healthy(context.Background(), []net.IP{net.ParseIP("127.0.0.1"), net.ParseIP("127.0.0.2")})
+	if len(ips) != 1 || !ips[0].Equal(net.ParseIP("127.0.0.1")) {
+		t.Errorf("unexpected healthy addresses: %v", ips)
+	}
+
+	listener.Close()
+	checker.Check(context.Background())
+	ips = checker.Healthy(context.Background(), []net.IP{net.ParseIP("127.0.0.1")})
+	if len(ips) != 1 {
+		t.Errorf("expected fallback to all addresses, got %v", ips)
+	}
+
+	if _, err := staticip.NewHealthChecker(staticip.HealthCheckConfig{Protocol: "udp", Port: 80}, nil); err == nil {
+		t.Errorf("expected invalid protocol to be rejected")
+	}
+}
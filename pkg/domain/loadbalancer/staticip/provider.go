@@ -11,23 +11,86 @@ import (
 	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer"
 )
 
+const ReasonRegionUnavailable = "RegionUnavailable"
+
 type Provider struct {
-	IPAddresses []net.IP
+	IPAddresses   []net.IP
+	Regions       map[string][]net.IP
+	DefaultRegion string
+	HealthChecker *HealthChecker
 }
 
 func NewProvider(config Config) (*Provider, error) {
-	ips := make([]net.IP, len(config.IPAddresses))
-	for i, s := range config.IPAddresses {
+	ips, err := parseIPs(config.IPAddresses)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(config.Regions) == 0 {
+		if config.DefaultRegion != "" {
+			return nil, fmt.Errorf("default region '%s' is not configured", config.DefaultRegion)
+		}
+		healthChecker, err := newHealthChecker(config.HealthCheck, ips)
+		if err != nil {
+			return nil, err
+		}
+		return &Provider{
+			IPAddresses:   ips,
+			HealthChecker: healthChecker,
+		}, nil
+	}
+
+	if len(ips) > 0 {
+		return nil, fmt.Errorf("IP addresses must be configured in regions")
+	}
+	regions := map[string][]net.IP{}
+	var allIPs []net.IP
+	for region, addresses := range config.Regions {
+		if len(addresses) == 0 {
+			return nil, fmt.Errorf("region '%s' has no IP addresses", region)
+		}
+		regions[region], err = parseIPs(addresses)
+		if err != nil {
+			return nil, err
+		}
+		allIPs = append(allIPs, regions[region]...)
+	}
+	if _, ok := regions[config.DefaultRegion]; !ok {
+		return nil, fmt.Errorf("default region '%s' is not configured", config.DefaultRegion)
+	}
+
+	healthChecker, err := newHealthChecker(config.HealthCheck, allIPs)
+	if err != nil {
+		return nil, err
+	}
+	return &Provider{
+		Regions:       regions,
+		DefaultRegion: config.DefaultRegion,
+		HealthChecker: healthChecker,
+	}, nil
+}
+
+func newHealthChecker(config *HealthCheckConfig, ips []net.IP) (*HealthChecker, error) {
+	if config == nil {
+		return nil, nil
+	}
+	healthChecker, err := NewHealthChecker(*config, ips)
+	if err != nil {
+		return nil, fmt.Errorf("invalid health check: %w", err)
+	}
+	return healthChecker, nil
+}
+
+func parseIPs(addresses []string) ([]net.IP, error) {
+	ips := make([]net.IP, len(addresses))
+	for i, s := range addresses {
 		ip := net.ParseIP(s)
 		if ip == nil {
 			return nil, fmt.Errorf("IP address '%s' is not valid", s)
 		}
 		ips[i] = ip
 	}
-
-	return &Provider{
-		IPAddresses: ips,
-	}, nil
+	return ips, nil
 }
 
 var _ loadbalancer.Provider = &Provider{}
@@ -38,8 +101,30 @@ func (p *Provider) Provision(ctx context.Context, domain *domainv1beta1.CustomDo
 		return nil, err
 	}
 
-	dnsRecords := make([]loadbalancer.DNSRecord, len(p.IPAddresses))
-	for i, ip := range p.IPAddresses {
+	ips := p.IPAddresses
+	var region *string
+	if p.Regions != nil {
+		name := p.DefaultRegion
+		if domain.Spec.Region != nil {
+			name = *domain.Spec.Region
+		}
+		var ok bool
+		ips, ok = p.Regions[name]
+		if !ok {
+			return nil, &loadbalancer.ProvisionError{
+				Reason: ReasonRegionUnavailable,
+				Err:    fmt.Errorf("region '%s' is not available", name),
+			}
+		}
+		region = &name
+	}
+
+	if p.HealthChecker != nil {
+		ips = p.HealthChecker.Healthy(ctx, ips)
+	}
+
+	dnsRecords := make([]loadbalancer.DNSRecord, len(ips))
+	for i, ip := range ips {
 		var recordType string
 		if ip.To4() == nil {
 			recordType = "AAAA"
@@ -61,6 +146,7 @@ func (p *Provider) Provision(ctx context.Context, domain *domainv1beta1.CustomDo
 
 	return &loadbalancer.ProvisionResult{
 		DNSRecords: dnsRecords,
+		Region:     region,
 	}, nil
 }
 
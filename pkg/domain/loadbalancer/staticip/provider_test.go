@@ -0,0 +1,88 @@
+package staticip_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer/staticip"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestProvisionRegion(t *testing.T) {
+	provider, err := staticip.NewProvider(staticip.Config{
+		Regions: map[string][]string{
+			"us-east": {"192.0.2.1", "2001:db8::1"},
+			"eu-west": {"192.0.2.2"},
+		},
+		DefaultRegion: "us-east",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	domain := &domainv1beta1.CustomDomain{ObjectMeta: metav1.ObjectMeta{Name: "my-app.test"}}
+	result, err := provider.Provision(ctx, domain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Region == nil || *result.Region != "us-east" {
+		t.Errorf("expected default region, got %v", result.Region)
+	}
+	expected := []loadbalancer.DNSRecord{
+		{Name: "@", Type: "A", Value: "192.0.2.1"},
+		{Name: "@", Type: "AAAA", Value: "2001:db8::1"},
+	}
+	if !reflect.DeepEqual(result.DNSRecords, expected) {
+		t.Errorf("unexpected records: %v", result.DNSRecords)
+	}
+
+	domain.Spec.Region = pointer.StringPtr("eu-west")
+	result, err = provider.Provision(ctx, domain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = []loadbalancer.DNSRecord{
+		{Name: "@", Type: "A", Value: "192.0.2.2"},
+	}
+	if result.Region == nil || *result.Region != "eu-west" || !reflect.DeepEqual(result.DNSRecords, expected) {
+		t.Errorf("unexpected result: %v %v", result.Region, result.DNSRecords)
+	}
+
+	domain.Spec.Region = pointer.StringPtr("ap-south")
+	_, err = provider.Provision(ctx, domain)
+	if loadbalancer.ReasonOf(err) != staticip.ReasonRegionUnavailable {
+		t.Errorf("expected region unavailable error, got %v", err)
+	}
+}
+
+func TestProvisionWithoutRegions(t *testing.T) {
+	provider, err := staticip.NewProvider(staticip.Config{IPAddresses: []string{"192.0.2.1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	domain := &domainv1beta1.CustomDomain{ObjectMeta: metav1.ObjectMeta{Name: "www.my-app.test"}}
+	domain.Spec.Region = pointer.StringPtr("eu-west")
+	result, err := provider.Provision(context.Background(), domain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Region != nil {
+		t.Errorf("expected provider not region-aware, got %s", *result.Region)
+	}
+	if len(result.DNSRecords) != 1 || result.DNSRecords[0].Name != "www.my-app.test" {
+		t.Errorf("unexpected records: %v", result.DNSRecords)
+	}
+
+	if _, err := staticip.NewProvider(staticip.Config{
+		Regions:       map[string][]string{"us-east": {"192.0.2.1"}},
+		DefaultRegion: "eu-west",
+	}); err == nil {
+		t.Errorf("expected unknown default region to be rejected")
+	}
+}
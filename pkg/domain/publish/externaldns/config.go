@@ -0,0 +1,10 @@
+package externaldns
+
+type Config struct {
+	// Namespace is the namespace DNSEndpoint objects are created in.
+	Namespace string
+	// Zones are the zones records are emitted for, all domains if empty.
+	Zones []string
+	// TTL is the TTL of records in seconds, defaults to 300.
+	TTL int64
+}
@@ -0,0 +1,120 @@
+package externaldns
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	_ = domainv1beta1.AddToScheme(scheme)
+}
+
+var GroupVersionKind = schema.GroupVersionKind{
+	Group:   "externaldns.k8s.io",
+	Version: "v1alpha1",
+	Kind:    "DNSEndpoint",
+}
+
+const defaultTTL int64 = 300
+
+type Provider struct {
+	Config Config
+}
+
+func NewProvider(config Config) (*Provider, error) {
+	if config.Namespace == "" {
+		return nil, fmt.Errorf("namespace of DNSEndpoint is required")
+	}
+	if config.TTL < 0 {
+		return nil, fmt.Errorf("invalid TTL: %d", config.TTL)
+	}
+	if config.TTL == 0 {
+		config.TTL = defaultTTL
+	}
+	return &Provider{Config: config}, nil
+}
+
+// Manages returns whether DNSEndpoint should be emitted for the domain.
+func (p *Provider) Manages(domain string) bool {
+	if len(p.Config.Zones) == 0 {
+		return true
+	}
+
+	domain = normalizeName(domain)
+	for _, zone := range p.Config.Zones {
+		zone = normalizeName(zone)
+		if domain == zone || strings.HasSuffix(domain, "."+zone) {
+			return true
+		}
+	}
+	return false
+}
+
+// MakeEmptyDNSEndpoint makes an empty DNSEndpoint object, used to read the
+// existing object of the domain.
+func (p *Provider) MakeEmptyDNSEndpoint() *unstructured.Unstructured {
+	endpoint := &unstructured.Unstructured{}
+	endpoint.SetGroupVersionKind(GroupVersionKind)
+	return endpoint
+}
+
+// MakeDNSEndpoint makes the DNSEndpoint object of the domain, records with
+// same name and type are merged into a single endpoint.
+func (p *Provider) MakeDNSEndpoint(domain *domainv1beta1.CustomDomain, records []domainv1beta1.CustomDomainDNSRecord) (*unstructured.Unstructured, error) {
+	type key struct{ name, recordType string }
+	var keys []key
+	targets := map[key][]interface{}{}
+	for _, record := range records {
+		name := record.Name
+		if name == "@" {
+			name = domain.Name
+		}
+		k := key{name: normalizeName(name), recordType: record.Type}
+		if _, ok := targets[k]; !ok {
+			keys = append(keys, k)
+		}
+		targets[k] = append(targets[k], record.Value)
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		if keys[i].name != keys[j].name {
+			return keys[i].name < keys[j].name
+		}
+		return keys[i].recordType < keys[j].recordType
+	})
+
+	var endpoints []interface{}
+	for _, k := range keys {
+		endpoints = append(endpoints, map[string]interface{}{
+			"dnsName":    k.name,
+			"recordType": k.recordType,
+			"recordTTL":  p.Config.TTL,
+			"targets":    targets[k],
+		})
+	}
+
+	endpoint := p.MakeEmptyDNSEndpoint()
+	endpoint.SetName(domain.Name)
+	endpoint.SetNamespace(p.Config.Namespace)
+	if err := unstructured.SetNestedSlice(endpoint.Object, endpoints, "spec", "endpoints"); err != nil {
+		return nil, err
+	}
+
+	if err := ctrl.SetControllerReference(domain, endpoint, scheme); err != nil {
+		return nil, err
+	}
+
+	return endpoint, nil
+}
+
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}
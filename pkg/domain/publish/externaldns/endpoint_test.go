@@ -0,0 +1,69 @@
+package externaldns_test
+
+import (
+	"reflect"
+	"testing"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/publish/externaldns"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestMakeDNSEndpoint(t *testing.T) {
+	provider, err := externaldns.NewProvider(externaldns.Config{
+		Namespace: "k8s-controller-system",
+		Zones:     []string{"apps.example.test"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !provider.Manages("my-app.apps.example.test") {
+		t.Errorf("expected domain in zone to be managed")
+	}
+	if provider.Manages("my-app.test") || provider.Manages("otherapps.example.test") {
+		t.Errorf("expected domain outside zone to be unmanaged")
+	}
+
+	domain := &domainv1beta1.CustomDomain{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-app.apps.example.test", UID: "domain-uid"},
+	}
+	endpoint, err := provider.MakeDNSEndpoint(domain, []domainv1beta1.CustomDomainDNSRecord{
+		{Name: "@", Type: "A", Value: "10.0.0.1"},
+		{Name: "@", Type: "AAAA", Value: "fd00::1"},
+		{Name: "@", Type: "A", Value: "10.0.0.2"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if endpoint.GetKind() != "DNSEndpoint" || endpoint.GetNamespace() != "k8s-controller-system" || endpoint.GetName() != domain.Name {
+		t.Errorf("unexpected object: %s %s/%s", endpoint.GetKind(), endpoint.GetNamespace(), endpoint.GetName())
+	}
+	if owners := endpoint.GetOwnerReferences(); len(owners) != 1 || owners[0].UID != domain.UID {
+		t.Errorf("expected owner reference to domain, got %v", owners)
+	}
+
+	endpoints, _, err := unstructured.NestedSlice(endpoint.Object, "spec", "endpoints")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []interface{}{
+		map[string]interface{}{
+			"dnsName":    "my-app.apps.example.test",
+			"recordType": "A",
+			"recordTTL":  int64(300),
+			"targets":    []interface{}{"10.0.0.1", "10.0.0.2"},
+		},
+		map[string]interface{}{
+			"dnsName":    "my-app.apps.example.test",
+			"recordType": "AAAA",
+			"recordTTL":  int64(300),
+			"targets":    []interface{}{"fd00::1"},
+		},
+	}
+	if !reflect.DeepEqual(endpoints, expected) {
+		t.Errorf("unexpected endpoints: %v", endpoints)
+	}
+}
@@ -0,0 +1,21 @@
+package publish
+
+import (
+	"context"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+)
+
+// Provider publishes DNS records of domains into managed zones. Record name
+// '@' refers to the domain itself.
+type Provider interface {
+	// Manages returns whether the domain is within a managed zone.
+	Manages(domain string) bool
+	// AllowsApp returns whether verification records of the app may be
+	// published for the domain, before the domain accepts the app.
+	AllowsApp(domain string, app string) bool
+	// Publish adds TXT records, and replaces other records of same name.
+	Publish(ctx context.Context, domain string, records []domainv1beta1.CustomDomainDNSRecord) error
+	// Unpublish removes the records.
+	Unpublish(ctx context.Context, domain string, records []domainv1beta1.CustomDomainDNSRecord) error
+}
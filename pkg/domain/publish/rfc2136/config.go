@@ -0,0 +1,30 @@
+package rfc2136
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type Config struct {
+	// Zones are the zones records are published into.
+	Zones []ZoneConfig
+	// TTL is the TTL of published records in seconds, defaults to 300.
+	TTL uint32
+	// Timeout is the timeout of each update request.
+	Timeout *metav1.Duration
+}
+
+type ZoneConfig struct {
+	// Zone is the name of the zone, e.g. example.com.
+	Zone string
+	// Nameserver is the address of primary nameserver accepting updates.
+	Nameserver string
+	// TSIGKeyName is the name of TSIG key.
+	TSIGKeyName string
+	// TSIGSecret is the base64-encoded TSIG secret.
+	TSIGSecret string
+	// TSIGAlgorithm is the TSIG algorithm, defaults to hmac-sha256.
+	TSIGAlgorithm string
+	// Apps are the apps allowed to claim domains within the zone; their
+	// verification records are published before they are accepted.
+	Apps []string
+}
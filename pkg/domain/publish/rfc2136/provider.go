@@ -0,0 +1,238 @@
+package rfc2136
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/publish"
+	"github.com/skygeario/k8s-controller/pkg/util/slice"
+)
+
+const (
+	DefaultTTL            = 300
+	DefaultRequestTimeout = 10 * time.Second
+	// tsigFudge is the permitted clock skew of TSIG in seconds.
+	tsigFudge = 300
+)
+
+type zone struct {
+	Name          string
+	Nameserver    string
+	TSIGKeyName   string
+	TSIGSecret    string
+	TSIGAlgorithm string
+	Apps          []string
+}
+
+type Provider struct {
+	Zones   []zone
+	TTL     uint32
+	Timeout time.Duration
+}
+
+func NewProvider(config Config) (*Provider, error) {
+	zones := make([]zone, len(config.Zones))
+	for i, z := range config.Zones {
+		if z.Zone == "" {
+			return nil, fmt.Errorf("zone name is missing")
+		}
+		if _, _, err := net.SplitHostPort(z.Nameserver); err != nil {
+			return nil, fmt.Errorf("nameserver of zone '%s' is invalid: %w", z.Zone, err)
+		}
+		if z.TSIGKeyName == "" || z.TSIGSecret == "" {
+			return nil, fmt.Errorf("TSIG key of zone '%s' is missing", z.Zone)
+		}
+		if _, err := base64.StdEncoding.DecodeString(z.TSIGSecret); err != nil {
+			return nil, fmt.Errorf("TSIG secret of zone '%s' is invalid: %w", z.Zone, err)
+		}
+
+		algorithm := dns.HmacSHA256
+		if z.TSIGAlgorithm != "" {
+			algorithm = dns.Fqdn(strings.ToLower(z.TSIGAlgorithm))
+		}
+		switch algorithm {
+		case dns.HmacSHA1, dns.HmacSHA256, dns.HmacSHA512:
+		default:
+			return nil, fmt.Errorf("TSIG algorithm '%s' is not supported", z.TSIGAlgorithm)
+		}
+
+		zones[i] = zone{
+			Name:          canonicalName(z.Zone),
+			Nameserver:    z.Nameserver,
+			TSIGKeyName:   canonicalName(z.TSIGKeyName),
+			TSIGSecret:    z.TSIGSecret,
+			TSIGAlgorithm: algorithm,
+			Apps:          z.Apps,
+		}
+	}
+
+	ttl := uint32(DefaultTTL)
+	if config.TTL != 0 {
+		ttl = config.TTL
+	}
+	timeout := DefaultRequestTimeout
+	if config.Timeout != nil {
+		timeout = config.Timeout.Duration
+	}
+
+	return &Provider{
+		Zones:   zones,
+		TTL:     ttl,
+		Timeout: timeout,
+	}, nil
+}
+
+var _ publish.Provider = &Provider{}
+
+func (p *Provider) Manages(domain string) bool {
+	return p.lookupZone(domain) != nil
+}
+
+func (p *Provider) AllowsApp(domain string, app string) bool {
+	z := p.lookupZone(domain)
+	if z == nil {
+		return false
+	}
+	return slice.ContainsString(z.Apps, app)
+}
+
+func (p *Provider) Publish(ctx context.Context, domain string, records []domainv1beta1.CustomDomainDNSRecord) error {
+	z := p.lookupZone(domain)
+	if z == nil {
+		return fmt.Errorf("domain '%s' is not in a managed zone", domain)
+	}
+
+	rrs, err := p.makeRRs(z, domain, records)
+	if err != nil {
+		return err
+	}
+
+	var replaced []dns.RR
+	replacedNames := map[string]bool{}
+	for _, rr := range rrs {
+		hdr := rr.Header()
+		switch hdr.Rrtype {
+		case dns.TypeTXT:
+			// TXT records of same name may be published by other registrations
+		default:
+			if replacedNames[hdr.Name] {
+				continue
+			}
+			replacedNames[hdr.Name] = true
+			// CNAME records cannot co-exist with address records
+			for _, t := range []uint16{dns.TypeCNAME, dns.TypeA, dns.TypeAAAA} {
+				replaced = append(replaced, &dns.ANY{Hdr: dns.RR_Header{Name: hdr.Name, Rrtype: t}})
+			}
+		}
+	}
+
+	msg := new(dns.Msg)
+	msg.SetUpdate(z.Name)
+	msg.RemoveRRset(replaced)
+	msg.Insert(rrs)
+
+	return p.exchange(ctx, z, msg)
+}
+
+func (p *Provider) Unpublish(ctx context.Context, domain string, records []domainv1beta1.CustomDomainDNSRecord) error {
+	z := p.lookupZone(domain)
+	if z == nil {
+		return fmt.Errorf("domain '%s' is not in a managed zone", domain)
+	}
+
+	rrs, err := p.makeRRs(z, domain, records)
+	if err != nil {
+		return err
+	}
+
+	msg := new(dns.Msg)
+	msg.SetUpdate(z.Name)
+	msg.Remove(rrs)
+
+	return p.exchange(ctx, z, msg)
+}
+
+func (p *Provider) lookupZone(domain string) *zone {
+	name := canonicalName(domain)
+	var match *zone
+	for i, z := range p.Zones {
+		if !dns.IsSubDomain(z.Name, name) {
+			continue
+		}
+		// most specific zone is used
+		if match == nil || len(z.Name) > len(match.Name) {
+			match = &p.Zones[i]
+		}
+	}
+	return match
+}
+
+func (p *Provider) makeRRs(z *zone, domain string, records []domainv1beta1.CustomDomainDNSRecord) ([]dns.RR, error) {
+	rrs := make([]dns.RR, len(records))
+	for i, record := range records {
+		name := record.Name
+		if name == "@" {
+			name = domain
+		}
+		name = canonicalName(name)
+		if !dns.IsSubDomain(z.Name, name) {
+			return nil, fmt.Errorf("record '%s' is not in zone '%s'", name, z.Name)
+		}
+
+		hdr := dns.RR_Header{Name: name, Class: dns.ClassINET, Ttl: p.TTL}
+		switch record.Type {
+		case "A":
+			ip := net.ParseIP(record.Value).To4()
+			if ip == nil {
+				return nil, fmt.Errorf("IPv4 address '%s' is invalid", record.Value)
+			}
+			hdr.Rrtype = dns.TypeA
+			rrs[i] = &dns.A{Hdr: hdr, A: ip}
+		case "AAAA":
+			ip := net.ParseIP(record.Value)
+			if ip == nil || ip.To4() != nil {
+				return nil, fmt.Errorf("IPv6 address '%s' is invalid", record.Value)
+			}
+			hdr.Rrtype = dns.TypeAAAA
+			rrs[i] = &dns.AAAA{Hdr: hdr, AAAA: ip}
+		case "CNAME":
+			hdr.Rrtype = dns.TypeCNAME
+			rrs[i] = &dns.CNAME{Hdr: hdr, Target: dns.Fqdn(record.Value)}
+		case "TXT":
+			hdr.Rrtype = dns.TypeTXT
+			rrs[i] = &dns.TXT{Hdr: hdr, Txt: []string{record.Value}}
+		default:
+			return nil, fmt.Errorf("record type '%s' is not supported", record.Type)
+		}
+	}
+	return rrs, nil
+}
+
+func (p *Provider) exchange(ctx context.Context, z *zone, msg *dns.Msg) error {
+	msg.SetTsig(z.TSIGKeyName, z.TSIGAlgorithm, tsigFudge, time.Now().Unix())
+
+	client := &dns.Client{
+		Net:        "tcp",
+		Timeout:    p.Timeout,
+		TsigSecret: map[string]string{z.TSIGKeyName: z.TSIGSecret},
+	}
+	resp, _, err := client.ExchangeContext(ctx, msg, z.Nameserver)
+	if err != nil {
+		return fmt.Errorf("cannot update zone '%s': %w", z.Name, err)
+	}
+	if resp.Rcode != dns.RcodeSuccess {
+		return fmt.Errorf("cannot update zone '%s': %s", z.Name, dns.RcodeToString[resp.Rcode])
+	}
+	return nil
+}
+
+func canonicalName(name string) string {
+	return dns.Fqdn(strings.ToLower(name))
+}
@@ -0,0 +1,160 @@
+package rfc2136_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	internaltest "github.com/skygeario/k8s-controller/internal/test"
+	"github.com/skygeario/k8s-controller/pkg/domain/publish/rfc2136"
+)
+
+const testSecret = "c2VjcmV0LWtleS1mb3ItdGVzdGluZw=="
+
+func newProvider(t *testing.T, server *internaltest.DNSUpdateServer, secret string) *rfc2136.Provider {
+	provider, err := rfc2136.NewProvider(rfc2136.Config{
+		Zones: []rfc2136.ZoneConfig{{
+			Zone:        "my-app.test",
+			Nameserver:  server.Addr,
+			TSIGKeyName: "update-key",
+			TSIGSecret:  secret,
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return provider
+}
+
+func TestPublishAndUnpublish(t *testing.T) {
+	server, err := internaltest.NewDNSUpdateServer("my-app.test", "update-key", testSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	provider := newProvider(t, server, testSecret)
+	ctx := context.Background()
+
+	if !provider.Manages("www.my-app.test") || provider.Manages("other-app.test") {
+		t.Errorf("unexpected managed zones")
+	}
+
+	expect := func(name string, rrType string, values ...string) {
+		t.Helper()
+		if actual := server.Records(name, rrType); !reflect.DeepEqual(actual, values) {
+			t.Errorf("expected %s %s records to be %v, got %v", name, rrType, values, actual)
+		}
+	}
+
+	err = provider.Publish(ctx, "my-app.test", []domainv1beta1.CustomDomainDNSRecord{
+		{Name: "@", Type: "A", Value: "192.0.2.1"},
+		{Name: "_skygear.my-app.test", Type: "TXT", Value: "token-a"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = provider.Publish(ctx, "my-app.test", []domainv1beta1.CustomDomainDNSRecord{
+		{Name: "@", Type: "A", Value: "192.0.2.2"},
+		{Name: "@", Type: "AAAA", Value: "2001:db8::1"},
+		{Name: "_skygear.my-app.test", Type: "TXT", Value: "token-b"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect("my-app.test", "A", "192.0.2.2")
+	expect("my-app.test", "AAAA", "2001:db8::1")
+	expect("_skygear.my-app.test", "TXT", "token-a", "token-b")
+
+	err = provider.Publish(ctx, "www.my-app.test", []domainv1beta1.CustomDomainDNSRecord{
+		{Name: "www.my-app.test", Type: "A", Value: "192.0.2.1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = provider.Publish(ctx, "www.my-app.test", []domainv1beta1.CustomDomainDNSRecord{
+		{Name: "www.my-app.test", Type: "CNAME", Value: "cdn.test"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect("www.my-app.test", "A")
+	expect("www.my-app.test", "CNAME", "cdn.test")
+
+	err = provider.Unpublish(ctx, "my-app.test", []domainv1beta1.CustomDomainDNSRecord{
+		{Name: "_skygear.my-app.test", Type: "TXT", Value: "token-a"},
+		{Name: "@", Type: "AAAA", Value: "2001:db8::1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect("my-app.test", "A", "192.0.2.2")
+	expect("my-app.test", "AAAA")
+	expect("_skygear.my-app.test", "TXT", "token-b")
+
+	err = provider.Publish(ctx, "my-app.test", []domainv1beta1.CustomDomainDNSRecord{
+		{Name: "_skygear.other-app.test", Type: "TXT", Value: "token"},
+	})
+	if err == nil {
+		t.Errorf("expected record outside zone to be rejected")
+	}
+}
+
+func TestPublishWithInvalidKey(t *testing.T) {
+	server, err := internaltest.NewDNSUpdateServer("my-app.test", "update-key", testSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	provider := newProvider(t, server, "aW52YWxpZC1zZWNyZXQ=")
+	err = provider.Publish(context.Background(), "my-app.test", []domainv1beta1.CustomDomainDNSRecord{
+		{Name: "@", Type: "A", Value: "192.0.2.1"},
+	})
+	if err == nil {
+		t.Errorf("expected update to be rejected")
+	}
+	if n := server.NumUpdates(); n != 0 {
+		t.Errorf("unexpected %d updates", n)
+	}
+}
+
+func TestAllowsApp(t *testing.T) {
+	provider, err := rfc2136.NewProvider(rfc2136.Config{
+		Zones: []rfc2136.ZoneConfig{
+			{
+				Zone:        "my-app.test",
+				Nameserver:  "127.0.0.1:53",
+				TSIGKeyName: "update-key",
+				TSIGSecret:  testSecret,
+				Apps:        []string{"my-app"},
+			},
+			{
+				Zone:        "shared.my-app.test",
+				Nameserver:  "127.0.0.1:53",
+				TSIGKeyName: "update-key",
+				TSIGSecret:  testSecret,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		domain  string
+		app     string
+		allowed bool
+	}{
+		{"www.my-app.test", "my-app", true},
+		{"www.my-app.test", "other-app", false},
+		{"www.shared.my-app.test", "my-app", false},
+		{"www.other-app.test", "my-app", false},
+	}
+	for _, c := range cases {
+		if allowed := provider.AllowsApp(c.domain, c.app); allowed != c.allowed {
+			t.Errorf("expected app %s allowed for %s to be %v", c.app, c.domain, c.allowed)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package acme
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type Config struct {
+	// DirectoryURL is the URL of ACME directory, e.g.
+	// https://acme-v02.api.letsencrypt.org/directory.
+	DirectoryURL string
+	// Email is the contact email address of the ACME account.
+	Email string
+	// Namespace is the namespace of account Secret and challenge Service,
+	// where challenge Ingresses are created.
+	Namespace string
+	// AccountSecretName is the name of Secret storing the ACME account key,
+	// defaults to acme-account.
+	AccountSecretName string
+	// ListenAddress is the address the challenge server binds to.
+	ListenAddress string
+	// ServiceName is the name of Service exposing the challenge server.
+	ServiceName string
+	// ServicePort is the port of Service exposing the challenge server.
+	ServicePort int
+	// RenewBefore is the remaining validity period at which certificates are
+	// renewed, defaults to 720h.
+	RenewBefore *metav1.Duration
+	// PollInterval is the interval of polling pending orders, defaults to
+	// 10s.
+	PollInterval *metav1.Duration
+}
@@ -0,0 +1,690 @@
+package acme
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/acme"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/ingress"
+	domaintls "github.com/skygeario/k8s-controller/pkg/domain/tls"
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	_ = domainv1beta1.AddToScheme(scheme)
+}
+
+// ChallengePathPrefix is the path prefix of HTTP-01 challenge requests.
+const ChallengePathPrefix = "/.well-known/acme-challenge/"
+
+// AccountKeyKey is the key of ACME account private key in account Secret.
+const AccountKeyKey = "account.key"
+
+const (
+	ReasonChallengeUnsupported = "ChallengeUnsupported"
+	ReasonChallengeUnreachable = "ChallengeUnreachable"
+	ReasonOrderInvalid         = "OrderInvalid"
+	ReasonOrderBackoff         = "OrderBackoff"
+)
+
+// Annotations of registration recording consecutive failed orders, so that
+// new orders are placed with exponential backoff.
+const (
+	AnnotationOrderFailures        = "acme.domain.skygear.io/order-failures"
+	AnnotationLastOrderFailureTime = "acme.domain.skygear.io/last-order-failure-time"
+)
+
+const (
+	defaultAccountSecretName = "acme-account"
+	defaultRenewBefore       = 30 * 24 * time.Hour
+	defaultPollInterval      = 10 * time.Second
+	defaultFailureBackoff    = time.Minute
+	maxFailureBackoff        = 24 * time.Hour
+	challengeCheckTimeout    = 10 * time.Second
+	requestTimeout           = 30 * time.Second
+	challengeType            = "http-01"
+)
+
+// order is an in-progress ACME order of a registration. Orders are kept only
+// in memory of the provider: orders in progress are not shared by replicas,
+// and are placed again after restart.
+type order struct {
+	URI        string
+	Key        *ecdsa.PrivateKey
+	Challenges []*acme.Challenge
+	Accepted   bool
+}
+
+type Provider struct {
+	KubeClient      client.Client
+	IngressProvider ingress.Provider
+	HTTPClient      *http.Client
+	// ChallengeClient is used to check challenge responses are reachable
+	// before accepting challenges.
+	ChallengeClient   *http.Client
+	Now               func() time.Time
+	DirectoryURL      string
+	Email             string
+	Namespace         string
+	AccountSecretName string
+	ServiceName       string
+	ServicePort       int
+	RenewBefore       time.Duration
+	PollInterval      time.Duration
+	// FailureBackoff is the delay of placing new order after an order
+	// failed, doubled on each consecutive failure.
+	FailureBackoff time.Duration
+
+	lock       sync.Mutex
+	accountKey *ecdsa.PrivateKey
+	client     *acme.Client
+	orders     map[types.NamespacedName]*order
+}
+
+func NewProvider(client client.Client, ingressProvider ingress.Provider, config Config) (*Provider, error) {
+	if config.DirectoryURL == "" {
+		return nil, fmt.Errorf("ACME directory URL is required")
+	}
+	if config.Namespace == "" || config.ServiceName == "" {
+		return nil, fmt.Errorf("challenge service is required")
+	}
+
+	p := &Provider{
+		KubeClient:        client,
+		IngressProvider:   ingressProvider,
+		HTTPClient:        http.DefaultClient,
+		ChallengeClient:   &http.Client{Timeout: challengeCheckTimeout},
+		Now:               time.Now,
+		DirectoryURL:      config.DirectoryURL,
+		Email:             config.Email,
+		Namespace:         config.Namespace,
+		AccountSecretName: config.AccountSecretName,
+		ServiceName:       config.ServiceName,
+		ServicePort:       config.ServicePort,
+		RenewBefore:       defaultRenewBefore,
+		PollInterval:      defaultPollInterval,
+		FailureBackoff:    defaultFailureBackoff,
+		orders:            map[types.NamespacedName]*order{},
+	}
+	if p.AccountSecretName == "" {
+		p.AccountSecretName = defaultAccountSecretName
+	}
+	if config.RenewBefore != nil {
+		p.RenewBefore = config.RenewBefore.Duration
+	}
+	if config.PollInterval != nil {
+		p.PollInterval = config.PollInterval.Duration
+	}
+	return p, nil
+}
+
+var _ domaintls.Provider = &Provider{}
+
+func (p *Provider) Provision(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (*domaintls.ProvisionResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	secretName := certSecretName(reg)
+	leaf, err := p.getCertificate(ctx, reg, secretName)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *domaintls.ProvisionResult
+	if leaf != nil {
+		renewTime := leaf.NotAfter.Add(-p.RenewBefore)
+		if p.Now().Before(renewTime) {
+			if err := p.cancelOrder(ctx, reg); err != nil {
+				return nil, err
+			}
+			return &domaintls.ProvisionResult{CertSecretName: secretName, RequeueTime: &renewTime}, nil
+		}
+		// Existing certificate is used until renewed.
+		result = &domaintls.ProvisionResult{CertSecretName: secretName}
+	}
+
+	leaf, err = p.processOrder(ctx, reg, secretName)
+	if err != nil {
+		if result != nil {
+			pollTime := p.Now().Add(p.PollInterval)
+			result.RequeueTime = &pollTime
+		}
+		return result, err
+	}
+	if leaf != nil {
+		renewTime := leaf.NotAfter.Add(-p.RenewBefore)
+		return &domaintls.ProvisionResult{CertSecretName: secretName, RequeueTime: &renewTime}, nil
+	}
+
+	if result != nil {
+		pollTime := p.Now().Add(p.PollInterval)
+		result.RequeueTime = &pollTime
+	}
+	return result, nil
+}
+
+func (p *Provider) Release(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (bool, error) {
+	if err := p.cancelOrder(ctx, reg); err != nil {
+		return false, err
+	}
+
+	var secret corev1.Secret
+	err := p.KubeClient.Get(ctx, types.NamespacedName{Namespace: reg.Namespace, Name: certSecretName(reg)}, &secret)
+	if apierrors.IsNotFound(err) {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+	if !metav1.IsControlledBy(&secret, reg) {
+		return true, nil
+	}
+
+	if err := p.KubeClient.Delete(ctx, &secret); err != nil && !apierrors.IsNotFound(err) {
+		return false, err
+	}
+	return true, nil
+}
+
+func certSecretName(reg *domainv1beta1.CustomDomainRegistration) string {
+	return reg.Name + "-acme-tls"
+}
+
+// getCertificate returns the leaf certificate in the certificate Secret if it
+// is usable for the domain, or nil otherwise.
+func (p *Provider) getCertificate(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration, secretName string) (*x509.Certificate, error) {
+	var secret corev1.Secret
+	err := p.KubeClient.Get(ctx, types.NamespacedName{Namespace: reg.Namespace, Name: secretName}, &secret)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if !metav1.IsControlledBy(&secret, reg) {
+		return nil, fmt.Errorf("certificate secret '%s' is not managed by the registration", secretName)
+	}
+
+	chain, err := domaintls.ParseCertificateChain(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		return nil, nil
+	}
+	leaf := chain[0]
+	if leaf.VerifyHostname(reg.Spec.DomainName) != nil || !p.Now().Before(leaf.NotAfter) {
+		return nil, nil
+	}
+	return leaf, nil
+}
+
+// processOrder advances the ACME order of the registration by one step, and
+// returns the leaf certificate when issued.
+func (p *Provider) processOrder(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration, secretName string) (*x509.Certificate, error) {
+	client, err := p.acmeClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	key := types.NamespacedName{Namespace: reg.Namespace, Name: reg.Name}
+	p.lock.Lock()
+	o := p.orders[key]
+	p.lock.Unlock()
+
+	if o == nil {
+		if failures, orderTime := p.nextOrderTime(reg); p.Now().Before(orderTime) {
+			return nil, provisionError(ReasonOrderBackoff, "ACME order failed %d times, retrying after %s", failures, orderTime.Format(time.RFC3339))
+		}
+		if err := p.updateChallengeIngress(ctx, reg); err != nil {
+			return nil, err
+		}
+		o, err = p.newOrder(ctx, client, reg)
+		if err != nil {
+			return nil, p.orderError(ctx, reg, err)
+		}
+		p.lock.Lock()
+		p.orders[key] = o
+		p.lock.Unlock()
+		// Challenges are accepted after the challenge Ingress is picked up.
+		return nil, nil
+	}
+
+	if !o.Accepted {
+		// Failed challenges invalidate the order, so challenges are accepted
+		// only after the responses are reachable.
+		for _, chal := range o.Challenges {
+			if err := p.checkChallenge(ctx, client, reg.Spec.DomainName, chal); err != nil {
+				return nil, provisionError(ReasonChallengeUnreachable, "HTTP-01 challenge response is not reachable: %v", err)
+			}
+		}
+		for _, chal := range o.Challenges {
+			if _, err := client.Accept(ctx, chal); err != nil {
+				return nil, p.orderError(ctx, reg, err)
+			}
+		}
+		o.Accepted = true
+		return nil, nil
+	}
+
+	ao, err := client.GetOrder(ctx, o.URI)
+	if err != nil {
+		return nil, p.orderError(ctx, reg, err)
+	}
+
+	var der [][]byte
+	switch ao.Status {
+	case acme.StatusReady:
+		csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+			Subject:  pkix.Name{CommonName: reg.Spec.DomainName},
+			DNSNames: []string{reg.Spec.DomainName},
+		}, o.Key)
+		if err != nil {
+			return nil, err
+		}
+		der, _, err = client.CreateOrderCert(ctx, ao.FinalizeURL, csr, true)
+		if err != nil {
+			return nil, p.orderError(ctx, reg, err)
+		}
+	case acme.StatusValid:
+		der, err = client.FetchCert(ctx, ao.CertURL, true)
+		if err != nil {
+			return nil, p.orderError(ctx, reg, err)
+		}
+	case acme.StatusInvalid:
+		if err := p.cancelOrder(ctx, reg); err != nil {
+			return nil, err
+		}
+		if err := p.recordFailure(ctx, reg); err != nil {
+			return nil, err
+		}
+		var reason error = errors.New("unknown error")
+		if ao.Error != nil {
+			reason = ao.Error
+		}
+		return nil, provisionError(ReasonOrderInvalid, "ACME order is invalid: %v", reason)
+	default:
+		return nil, nil
+	}
+
+	leaf, err := p.writeCertificate(ctx, reg, secretName, der, o.Key)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.cancelOrder(ctx, reg); err != nil {
+		return nil, err
+	}
+	if err := p.clearFailures(ctx, reg); err != nil {
+		return nil, err
+	}
+	return leaf, nil
+}
+
+func (p *Provider) newOrder(ctx context.Context, client *acme.Client, reg *domainv1beta1.CustomDomainRegistration) (*order, error) {
+	ao, err := client.AuthorizeOrder(ctx, acme.DomainIDs(reg.Spec.DomainName))
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	o := &order{URI: ao.URI, Key: key}
+
+	for _, authzURL := range ao.AuthzURLs {
+		authz, err := client.GetAuthorization(ctx, authzURL)
+		if err != nil {
+			return nil, err
+		}
+		if authz.Status != acme.StatusPending {
+			continue
+		}
+
+		var challenge *acme.Challenge
+		for _, chal := range authz.Challenges {
+			if chal.Type == challengeType {
+				challenge = chal
+				break
+			}
+		}
+		if challenge == nil {
+			return nil, provisionError(ReasonChallengeUnsupported, "HTTP-01 challenge is not offered for '%s'", authz.Identifier.Value)
+		}
+		o.Challenges = append(o.Challenges, challenge)
+	}
+
+	return o, nil
+}
+
+// orderError abandons the order if it is rejected by the ACME server, so a new
+// order would be created after backoff.
+func (p *Provider) orderError(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration, err error) error {
+	var acmeErr *acme.Error
+	var orderErr *acme.OrderError
+	if errors.As(err, &acmeErr) || errors.As(err, &orderErr) {
+		if cerr := p.cancelOrder(ctx, reg); cerr != nil {
+			return cerr
+		}
+		if rerr := p.recordFailure(ctx, reg); rerr != nil {
+			return rerr
+		}
+	}
+	return err
+}
+
+// nextOrderTime returns the number of consecutive failed orders, and the time
+// new order can be placed.
+func (p *Provider) nextOrderTime(reg *domainv1beta1.CustomDomainRegistration) (failures int, orderTime time.Time) {
+	failures, _ = strconv.Atoi(reg.Annotations[AnnotationOrderFailures])
+	lastFailureTime, err := time.Parse(time.RFC3339, reg.Annotations[AnnotationLastOrderFailureTime])
+	if failures <= 0 || err != nil {
+		return 0, time.Time{}
+	}
+
+	backoff := p.FailureBackoff
+	for i := 1; i < failures && backoff < maxFailureBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxFailureBackoff {
+		backoff = maxFailureBackoff
+	}
+	return failures, lastFailureTime.Add(backoff)
+}
+
+func (p *Provider) recordFailure(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) error {
+	failures, _ := p.nextOrderTime(reg)
+	return p.patchAnnotations(ctx, reg, map[string]*string{
+		AnnotationOrderFailures:        pointer.StringPtr(strconv.Itoa(failures + 1)),
+		AnnotationLastOrderFailureTime: pointer.StringPtr(p.Now().UTC().Format(time.RFC3339)),
+	})
+}
+
+func (p *Provider) clearFailures(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) error {
+	if _, ok := reg.Annotations[AnnotationOrderFailures]; !ok {
+		return nil
+	}
+	return p.patchAnnotations(ctx, reg, map[string]*string{
+		AnnotationOrderFailures:        nil,
+		AnnotationLastOrderFailureTime: nil,
+	})
+}
+
+// patchAnnotations sets annotations of the registration, or removes them if
+// the value is nil. Status of the registration is kept, since it is updated
+// by the controller afterwards.
+func (p *Provider) patchAnnotations(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration, annotations map[string]*string) error {
+	status := reg.Status
+	patch := client.MergeFrom(reg.DeepCopy())
+	if reg.Annotations == nil {
+		reg.Annotations = map[string]string{}
+	}
+	for key, value := range annotations {
+		if value == nil {
+			delete(reg.Annotations, key)
+		} else {
+			reg.Annotations[key] = *value
+		}
+	}
+	if err := p.KubeClient.Patch(ctx, reg, patch); err != nil {
+		return err
+	}
+	reg.Status = status
+	return nil
+}
+
+// checkChallenge checks the challenge response is served at the domain.
+func (p *Provider) checkChallenge(ctx context.Context, client *acme.Client, domain string, chal *acme.Challenge) error {
+	expected, err := client.HTTP01ChallengeResponse(chal.Token)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "http://"+domain+client.HTTP01ChallengePath(chal.Token), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.ChallengeClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(string(body)) != expected {
+		return fmt.Errorf("unexpected challenge response")
+	}
+	return nil
+}
+
+func (p *Provider) cancelOrder(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) error {
+	key := types.NamespacedName{Namespace: reg.Namespace, Name: reg.Name}
+	p.lock.Lock()
+	delete(p.orders, key)
+	p.lock.Unlock()
+
+	ingress, err := p.makeChallengeIngress(reg)
+	if err != nil {
+		return err
+	}
+	err = p.KubeClient.Get(ctx, types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}, ingress)
+	if apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if err := p.KubeClient.Delete(ctx, ingress); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func (p *Provider) makeChallengeIngress(reg *domainv1beta1.CustomDomainRegistration) (*networkingv1beta1.Ingress, error) {
+	return p.IngressProvider.MakeChallengeIngress(reg, p.Namespace, ChallengePathPrefix, networkingv1beta1.IngressBackend{
+		ServiceName: p.ServiceName,
+		ServicePort: intstr.FromInt(p.ServicePort),
+	})
+}
+
+func (p *Provider) updateChallengeIngress(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) error {
+	ingress, err := p.makeChallengeIngress(reg)
+	if err != nil {
+		return err
+	}
+
+	existingIngress := &networkingv1beta1.Ingress{}
+	err = p.KubeClient.Get(ctx, types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}, existingIngress)
+	if apierrors.IsNotFound(err) {
+		return p.KubeClient.Create(ctx, ingress)
+	} else if err != nil {
+		return err
+	}
+
+	existingIngress = existingIngress.DeepCopy()
+	existingIngress.Labels = ingress.Labels
+	existingIngress.Annotations = ingress.Annotations
+	existingIngress.Spec = ingress.Spec
+	return p.KubeClient.Update(ctx, existingIngress)
+}
+
+func (p *Provider) writeCertificate(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration, secretName string, der [][]byte, key *ecdsa.PrivateKey) (*x509.Certificate, error) {
+	if len(der) == 0 {
+		return nil, fmt.Errorf("no certificate issued")
+	}
+	leaf, err := x509.ParseCertificate(der[0])
+	if err != nil {
+		return nil, err
+	}
+
+	var certPEM []byte
+	for _, b := range der {
+		certPEM = append(certPEM, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: b})...)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	data := map[string][]byte{
+		corev1.TLSCertKey:       certPEM,
+		corev1.TLSPrivateKeyKey: keyPEM,
+	}
+
+	secret := &corev1.Secret{}
+	err = p.KubeClient.Get(ctx, types.NamespacedName{Namespace: reg.Namespace, Name: secretName}, secret)
+	if apierrors.IsNotFound(err) {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: reg.Namespace,
+				Name:      secretName,
+			},
+			Type: corev1.SecretTypeTLS,
+			Data: data,
+		}
+		if err := ctrl.SetControllerReference(reg, secret, scheme); err != nil {
+			return nil, err
+		}
+		if err := p.KubeClient.Create(ctx, secret); err != nil {
+			return nil, err
+		}
+		return leaf, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	secret = secret.DeepCopy()
+	secret.Data = data
+	if err := p.KubeClient.Update(ctx, secret); err != nil {
+		return nil, err
+	}
+	return leaf, nil
+}
+
+// acmeClient returns the ACME client of the account, registering the account
+// if needed.
+func (p *Provider) acmeClient(ctx context.Context) (*acme.Client, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.client != nil {
+		return p.client, nil
+	}
+
+	key, err := p.loadAccountKey(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &acme.Client{
+		Key:          key,
+		DirectoryURL: p.DirectoryURL,
+		HTTPClient:   p.HTTPClient,
+	}
+	var contact []string
+	if p.Email != "" {
+		contact = []string{"mailto:" + p.Email}
+	}
+	_, err = client.Register(ctx, &acme.Account{Contact: contact}, acme.AcceptTOS)
+	if err != nil && err != acme.ErrAccountAlreadyExists {
+		return nil, fmt.Errorf("cannot register ACME account: %w", err)
+	}
+
+	p.client = client
+	return client, nil
+}
+
+// AccountKey returns the ACME account key, or nil if not yet created.
+func (p *Provider) AccountKey(ctx context.Context) (*ecdsa.PrivateKey, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.loadAccountKey(ctx, false)
+}
+
+func (p *Provider) loadAccountKey(ctx context.Context, create bool) (*ecdsa.PrivateKey, error) {
+	if p.accountKey != nil {
+		return p.accountKey, nil
+	}
+
+	var secret corev1.Secret
+	err := p.KubeClient.Get(ctx, types.NamespacedName{Namespace: p.Namespace, Name: p.AccountSecretName}, &secret)
+	if apierrors.IsNotFound(err) {
+		if !create {
+			return nil, nil
+		}
+		return p.createAccountKey(ctx)
+	} else if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(secret.Data[AccountKeyKey])
+	if block == nil {
+		return nil, fmt.Errorf("invalid ACME account key")
+	}
+	key, err := x509.ParseECPrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ACME account key: %w", err)
+	}
+	p.accountKey = key
+	return key, nil
+}
+
+func (p *Provider) createAccountKey(ctx context.Context) (*ecdsa.PrivateKey, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: p.Namespace,
+			Name:      p.AccountSecretName,
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			AccountKeyKey: pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		},
+	}
+	if err := p.KubeClient.Create(ctx, secret); err != nil {
+		return nil, err
+	}
+	p.accountKey = key
+	return key, nil
+}
+
+func provisionError(reason string, format string, args ...interface{}) error {
+	return &domaintls.ProvisionError{
+		Reason: reason,
+		Err:    fmt.Errorf(format, args...),
+	}
+}
@@ -0,0 +1,344 @@
+package acme_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/internal/test"
+	domainingress "github.com/skygeario/k8s-controller/pkg/domain/ingress"
+	"github.com/skygeario/k8s-controller/pkg/domain/ingress/nginx"
+	domaintls "github.com/skygeario/k8s-controller/pkg/domain/tls"
+	"github.com/skygeario/k8s-controller/pkg/domain/tls/acme"
+)
+
+const challengeNamespace = "k8s-controller-system"
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = domainv1beta1.AddToScheme(scheme)
+}
+
+// newChallengeClient returns a client sending challenge requests to addr.
+func newChallengeClient(addr string) *http.Client {
+	return &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, addr)
+		},
+	}}
+}
+
+func newProvider(t *testing.T, server *test.ACMEServer, kubeClient client.Client) *acme.Provider {
+	ingressProvider, err := nginx.NewProvider()
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider, err := acme.NewProvider(kubeClient, ingressProvider, acme.Config{
+		DirectoryURL: server.DirectoryURL(),
+		Email:        "admin@example.com",
+		Namespace:    challengeNamespace,
+		ServiceName:  "acme-challenge",
+		ServicePort:  80,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider.FailureBackoff = time.Minute
+	return provider
+}
+
+func newRegistration() *domainv1beta1.CustomDomainRegistration {
+	return &domainv1beta1.CustomDomainRegistration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "www", UID: "www-uid"},
+		Spec:       domainv1beta1.CustomDomainRegistrationSpec{DomainName: "www.my-app.test"},
+	}
+}
+
+func getChallengeIngress(t *testing.T, kubeClient client.Client, reg *domainv1beta1.CustomDomainRegistration) *networkingv1beta1.Ingress {
+	var ingress networkingv1beta1.Ingress
+	err := kubeClient.Get(context.Background(), types.NamespacedName{Namespace: challengeNamespace, Name: "acme-www-uid"}, &ingress)
+	if apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	return &ingress
+}
+
+func getCertificate(t *testing.T, kubeClient client.Client, server *test.ACMEServer, now time.Time, reg *domainv1beta1.CustomDomainRegistration) *x509.Certificate {
+	var secret corev1.Secret
+	if err := kubeClient.Get(context.Background(), types.NamespacedName{Namespace: reg.Namespace, Name: "www-acme-tls"}, &secret); err != nil {
+		t.Fatal(err)
+	}
+	if secret.Type != corev1.SecretTypeTLS {
+		t.Errorf("unexpected secret type: %s", secret.Type)
+	}
+	if !metav1.IsControlledBy(&secret, reg) {
+		t.Errorf("secret is not controlled by registration")
+	}
+
+	keyPair, err := tls.X509KeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(keyPair.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(server.CACertificate())
+	if _, err := leaf.Verify(x509.VerifyOptions{DNSName: reg.Spec.DomainName, Roots: roots, CurrentTime: now}); err != nil {
+		t.Errorf("unexpected certificate: %s", err)
+	}
+	return leaf
+}
+
+func TestProvision(t *testing.T) {
+	server, err := test.NewACMEServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	reg := newRegistration()
+	kubeClient := fake.NewFakeClientWithScheme(scheme, reg)
+	provider := newProvider(t, server, kubeClient)
+	now := time.Now()
+	provider.Now = func() time.Time { return now }
+	server.Now = provider.Now
+
+	challengeServer := httptest.NewServer(&acme.ChallengeServer{Provider: provider})
+	defer challengeServer.Close()
+	server.ChallengeAddr = strings.TrimPrefix(challengeServer.URL, "http://")
+	provider.ChallengeClient = newChallengeClient(server.ChallengeAddr)
+
+	provision := func() *domaintls.ProvisionResult {
+		result, err := provider.Provision(context.Background(), reg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	// order is created
+	if result := provision(); result != nil {
+		t.Fatalf("unexpected result: %#v", result)
+	}
+	ingress := getChallengeIngress(t, kubeClient, reg)
+	if ingress == nil {
+		t.Fatal("challenge ingress is not created")
+	}
+	rule := ingress.Spec.Rules[0]
+	if rule.Host != "www.my-app.test" || rule.HTTP.Paths[0].Path != acme.ChallengePathPrefix {
+		t.Errorf("unexpected challenge ingress rule: %#v", rule)
+	}
+	if ingress.Labels[domainingress.LabelRegistrationNamespace] != "app" || ingress.Labels[domainingress.LabelRegistrationUID] != "www-uid" {
+		t.Errorf("unexpected challenge ingress labels: %v", ingress.Labels)
+	}
+	if server.NumAccounts() != 1 || server.NumOrders() != 1 {
+		t.Errorf("unexpected number of accounts and orders: %d, %d", server.NumAccounts(), server.NumOrders())
+	}
+
+	// challenges are accepted
+	if result := provision(); result != nil {
+		t.Fatalf("unexpected result: %#v", result)
+	}
+
+	// certificate is issued
+	result := provision()
+	if result == nil || result.CertSecretName != "www-acme-tls" {
+		t.Fatalf("unexpected result: %#v", result)
+	}
+	leaf := getCertificate(t, kubeClient, server, now, reg)
+	renewTime := leaf.NotAfter.Add(-30 * 24 * time.Hour)
+	if result.RequeueTime == nil || !result.RequeueTime.Equal(renewTime) {
+		t.Errorf("unexpected requeue time: %v", result.RequeueTime)
+	}
+	if getChallengeIngress(t, kubeClient, reg) != nil {
+		t.Error("challenge ingress is not deleted")
+	}
+
+	// certificate is reused before renewal
+	if result := provision(); result == nil || !result.RequeueTime.Equal(renewTime) {
+		t.Fatalf("unexpected result: %#v", result)
+	}
+	if server.NumOrders() != 1 {
+		t.Errorf("unexpected number of orders: %d", server.NumOrders())
+	}
+
+	// certificate is renewed, existing one is used until then
+	now = renewTime.Add(time.Minute)
+	for i := 0; i < 2; i++ {
+		result := provision()
+		if result == nil || result.CertSecretName != "www-acme-tls" {
+			t.Fatalf("unexpected result: %#v", result)
+		}
+		if !result.RequeueTime.Equal(now.Add(provider.PollInterval)) {
+			t.Errorf("unexpected requeue time: %v", result.RequeueTime)
+		}
+	}
+	if result := provision(); result == nil || result.RequeueTime.Before(now) {
+		t.Fatalf("unexpected result: %#v", result)
+	}
+	if server.NumAccounts() != 1 || server.NumOrders() != 2 {
+		t.Errorf("unexpected number of accounts and orders: %d, %d", server.NumAccounts(), server.NumOrders())
+	}
+	if renewed := getCertificate(t, kubeClient, server, now, reg); renewed.SerialNumber.Cmp(leaf.SerialNumber) == 0 {
+		t.Error("certificate is not renewed")
+	}
+
+	// certificate is released
+	ok, err := provider.Release(context.Background(), reg)
+	if err != nil || !ok {
+		t.Fatalf("unexpected release result: %v, %v", ok, err)
+	}
+	var secret corev1.Secret
+	err = kubeClient.Get(context.Background(), types.NamespacedName{Namespace: reg.Namespace, Name: "www-acme-tls"}, &secret)
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("certificate secret is not deleted: %v", err)
+	}
+}
+
+func TestProvisionInvalidChallenge(t *testing.T) {
+	server, err := test.NewACMEServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	reg := newRegistration()
+	kubeClient := fake.NewFakeClientWithScheme(scheme, reg)
+	provider := newProvider(t, server, kubeClient)
+	now := time.Now()
+	provider.Now = func() time.Time { return now }
+
+	// challenge responses are reachable by self-check, but not by the ACME
+	// server
+	challengeServer := httptest.NewServer(&acme.ChallengeServer{Provider: provider})
+	defer challengeServer.Close()
+	provider.ChallengeClient = newChallengeClient(strings.TrimPrefix(challengeServer.URL, "http://"))
+	invalidServer := httptest.NewServer(http.NotFoundHandler())
+	defer invalidServer.Close()
+	server.ChallengeAddr = strings.TrimPrefix(invalidServer.URL, "http://")
+
+	for i := 0; i < 2; i++ {
+		if _, err := provider.Provision(context.Background(), reg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := provider.Provision(context.Background(), reg)
+	if result != nil || domaintls.ReasonOf(err) != acme.ReasonOrderInvalid {
+		t.Fatalf("unexpected result: %#v, %v", result, err)
+	}
+	if getChallengeIngress(t, kubeClient, reg) != nil {
+		t.Error("challenge ingress is not deleted")
+	}
+	if reg.Annotations[acme.AnnotationOrderFailures] != "1" {
+		t.Errorf("unexpected annotations: %v", reg.Annotations)
+	}
+
+	// new order is not created during backoff
+	result, err = provider.Provision(context.Background(), reg)
+	if result != nil || domaintls.ReasonOf(err) != acme.ReasonOrderBackoff {
+		t.Fatalf("unexpected result: %#v, %v", result, err)
+	}
+	if server.NumOrders() != 1 {
+		t.Errorf("unexpected number of orders: %d", server.NumOrders())
+	}
+
+	// new order is created after backoff
+	now = now.Add(provider.FailureBackoff)
+	if _, err := provider.Provision(context.Background(), reg); err != nil {
+		t.Fatal(err)
+	}
+	if server.NumOrders() != 2 {
+		t.Errorf("unexpected number of orders: %d", server.NumOrders())
+	}
+}
+
+func TestProvisionBackoff(t *testing.T) {
+	server, err := test.NewACMEServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	reg := newRegistration()
+	kubeClient := fake.NewFakeClientWithScheme(scheme, reg)
+	provider := newProvider(t, server, kubeClient)
+	now := time.Now().Truncate(time.Second)
+	provider.Now = func() time.Time { return now }
+
+	reg.Annotations = map[string]string{
+		acme.AnnotationOrderFailures:        "3",
+		acme.AnnotationLastOrderFailureTime: now.Format(time.RFC3339),
+	}
+
+	// backoff is doubled on each consecutive failure
+	now = now.Add(4*provider.FailureBackoff - time.Second)
+	if _, err := provider.Provision(context.Background(), reg); domaintls.ReasonOf(err) != acme.ReasonOrderBackoff {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	now = now.Add(time.Second)
+	if _, err := provider.Provision(context.Background(), reg); err != nil {
+		t.Fatal(err)
+	}
+	if server.NumOrders() != 1 {
+		t.Errorf("unexpected number of orders: %d", server.NumOrders())
+	}
+}
+
+func TestProvisionUnreachableChallenge(t *testing.T) {
+	server, err := test.NewACMEServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	reg := newRegistration()
+	kubeClient := fake.NewFakeClientWithScheme(scheme, reg)
+	provider := newProvider(t, server, kubeClient)
+
+	challengeServer := httptest.NewServer(http.NotFoundHandler())
+	defer challengeServer.Close()
+	provider.ChallengeClient = newChallengeClient(strings.TrimPrefix(challengeServer.URL, "http://"))
+
+	if _, err := provider.Provision(context.Background(), reg); err != nil {
+		t.Fatal(err)
+	}
+
+	// challenges are not accepted until responses are reachable
+	for i := 0; i < 2; i++ {
+		result, err := provider.Provision(context.Background(), reg)
+		if result != nil || domaintls.ReasonOf(err) != acme.ReasonChallengeUnreachable {
+			t.Fatalf("unexpected result: %#v, %v", result, err)
+		}
+	}
+	if getChallengeIngress(t, kubeClient, reg) == nil {
+		t.Error("challenge ingress is deleted")
+	}
+	if server.NumOrders() != 1 {
+		t.Errorf("unexpected number of orders: %d", server.NumOrders())
+	}
+}
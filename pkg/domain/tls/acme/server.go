@@ -0,0 +1,67 @@
+package acme
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"golang.org/x/crypto/acme"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// ChallengeServer serves HTTP-01 challenge responses of the ACME account.
+type ChallengeServer struct {
+	Provider *Provider
+	Addr     string
+}
+
+var _ manager.Runnable = &ChallengeServer{}
+
+func (s *ChallengeServer) Start(stop <-chan struct{}) error {
+	server := &http.Server{Addr: s.Addr, Handler: s}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.ListenAndServe()
+	}()
+
+	select {
+	case <-stop:
+		return server.Shutdown(context.Background())
+	case err := <-errCh:
+		return err
+	}
+}
+
+func (s *ChallengeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.URL.Path, ChallengePathPrefix) {
+		http.NotFound(w, r)
+		return
+	}
+	token := strings.TrimPrefix(r.URL.Path, ChallengePathPrefix)
+	if token == "" || strings.Contains(token, "/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	key, err := s.Provider.AccountKey(r.Context())
+	if err != nil {
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	if key == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Key authorization is bound to our account key, so responding to any
+	// token does not allow others to obtain certificates; this also keeps
+	// the server stateless across replicas.
+	thumbprint, err := acme.JWKThumbprint(key.Public())
+	if err != nil {
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	_, _ = w.Write([]byte(token + "." + thumbprint))
+}
@@ -1,5 +1,12 @@
 package certmanager
 
 type Config struct {
+	// ClusterIssuerName is the ClusterIssuer used by default.
 	ClusterIssuerName string
+	// IssuerName is the Issuer in namespace of registration used by default,
+	// used if ClusterIssuerName is not specified.
+	IssuerName string
+	// AllowedClusterIssuers are the ClusterIssuers that registrations can
+	// select in addition to the default one.
+	AllowedClusterIssuers []string
 }
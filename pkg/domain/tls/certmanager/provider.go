@@ -2,13 +2,13 @@ package certmanager
 
 import (
 	"context"
+	"fmt"
 
-	cmutil "github.com/jetstack/cert-manager/pkg/api/util"
-	cm "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha2"
-	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -23,23 +23,55 @@ func init() {
 	_ = domainv1beta1.AddToScheme(scheme)
 }
 
+// CertificateGVK is the cert-manager Certificate kind. Certificates are
+// handled as unstructured objects to avoid depending on cert-manager module.
+var CertificateGVK = schema.GroupVersionKind{
+	Group:   "cert-manager.io",
+	Version: "v1",
+	Kind:    "Certificate",
+}
+
+const (
+	issuerGroup       = "cert-manager.io"
+	kindIssuer        = "Issuer"
+	kindClusterIssuer = "ClusterIssuer"
+)
+
+const ReasonIssuerNotAllowed = "IssuerNotAllowed"
+
 type Provider struct {
-	KubeClient        client.Client
-	ClusterIssuerName string
+	KubeClient            client.Client
+	ClusterIssuerName     string
+	IssuerName            string
+	AllowedClusterIssuers []string
 }
 
 func NewProvider(client client.Client, config Config) (*Provider, error) {
+	if config.ClusterIssuerName == "" && config.IssuerName == "" {
+		return nil, fmt.Errorf("either cluster issuer or issuer name is required")
+	}
+	if config.ClusterIssuerName != "" && config.IssuerName != "" {
+		return nil, fmt.Errorf("only one of cluster issuer and issuer name can be specified")
+	}
+
 	return &Provider{
-		KubeClient:        client,
-		ClusterIssuerName: config.ClusterIssuerName,
+		KubeClient:            client,
+		ClusterIssuerName:     config.ClusterIssuerName,
+		IssuerName:            config.IssuerName,
+		AllowedClusterIssuers: config.AllowedClusterIssuers,
 	}, nil
 }
 
 var _ tls.Provider = &Provider{}
 
 func (p *Provider) Provision(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (*tls.ProvisionResult, error) {
-	var cert cm.Certificate
-	err := p.KubeClient.Get(ctx, types.NamespacedName{Namespace: reg.Namespace, Name: reg.Name}, &cert)
+	issuerRef, err := p.issuerRef(reg)
+	if err != nil {
+		return nil, err
+	}
+
+	cert := newCertificate()
+	err = p.KubeClient.Get(ctx, types.NamespacedName{Namespace: reg.Namespace, Name: reg.Name}, cert)
 	if err != nil {
 		if !apierrors.IsNotFound(err) {
 			return nil, err
@@ -47,34 +79,51 @@ func (p *Provider) Provision(ctx context.Context, reg *domainv1beta1.CustomDomai
 	}
 
 	if apierrors.IsNotFound(err) {
-		cert.Namespace = reg.Namespace
-		cert.Name = reg.Name
-		cert.Spec.IssuerRef = cmmeta.ObjectReference{
-			Kind: "ClusterIssuer",
-			Name: p.ClusterIssuerName,
+		cert = newCertificate()
+		cert.SetNamespace(reg.Namespace)
+		cert.SetName(reg.Name)
+		cert.Object["spec"] = map[string]interface{}{
+			"secretName": reg.Name + "-tls",
+			"dnsNames":   []interface{}{reg.Spec.DomainName},
+			"issuerRef":  issuerRef,
 		}
-		cert.Spec.SecretName = reg.Name + "-tls"
-		cert.Spec.DNSNames = []string{reg.Spec.DomainName}
-		if err := ctrl.SetControllerReference(reg, &cert, scheme); err != nil {
+		if err := ctrl.SetControllerReference(reg, cert, scheme); err != nil {
 			return nil, err
 		}
-		if err := p.KubeClient.Create(ctx, &cert); err != nil {
+		if err := p.KubeClient.Create(ctx, cert); err != nil {
 			return nil, err
 		}
+		return nil, nil
 	}
 
-	if !cmutil.CertificateHasCondition(&cert, cm.CertificateCondition{Type: cm.CertificateConditionReady, Status: cmmeta.ConditionTrue}) {
+	currentIssuerRef, _, _ := unstructured.NestedStringMap(cert.Object, "spec", "issuerRef")
+	if !isSameIssuer(currentIssuerRef, issuerRef) {
+		// Certificate would be re-issued by the new issuer.
+		if err := unstructured.SetNestedField(cert.Object, issuerRef, "spec", "issuerRef"); err != nil {
+			return nil, err
+		}
+		if err := p.KubeClient.Update(ctx, cert); err != nil {
+			return nil, err
+		}
 		return nil, nil
 	}
 
+	if !isCertificateReady(cert) {
+		return nil, nil
+	}
+
+	secretName, _, err := unstructured.NestedString(cert.Object, "spec", "secretName")
+	if err != nil {
+		return nil, err
+	}
 	return &tls.ProvisionResult{
-		CertSecretName: cert.Spec.SecretName,
+		CertSecretName: secretName,
 	}, nil
 }
 
 func (p *Provider) Release(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (bool, error) {
-	var cert cm.Certificate
-	err := p.KubeClient.Get(ctx, types.NamespacedName{Namespace: reg.Namespace, Name: reg.Name}, &cert)
+	cert := newCertificate()
+	err := p.KubeClient.Get(ctx, types.NamespacedName{Namespace: reg.Namespace, Name: reg.Name}, cert)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			return true, nil
@@ -82,12 +131,86 @@ func (p *Provider) Release(ctx context.Context, reg *domainv1beta1.CustomDomainR
 		return false, err
 	}
 
-	if !metav1.IsControlledBy(&cert, reg) {
+	if !metav1.IsControlledBy(cert, reg) {
 		return true, nil
 	}
 
-	if err := p.KubeClient.Delete(ctx, &cert); err != nil {
+	if err := p.KubeClient.Delete(ctx, cert); err != nil {
 		return false, err
 	}
 	return true, nil
 }
+
+// issuerRef returns the issuer of the registration, which may be overridden
+// by the registration with an Issuer in its namespace or an allowed
+// ClusterIssuer.
+func (p *Provider) issuerRef(reg *domainv1beta1.CustomDomainRegistration) (map[string]interface{}, error) {
+	kind, name := kindClusterIssuer, p.ClusterIssuerName
+	if name == "" {
+		kind, name = kindIssuer, p.IssuerName
+	}
+
+	if ref := reg.Spec.DomainConfig.CertIssuer; ref != nil {
+		kind, name = ref.Kind, ref.Name
+		if kind == "" {
+			kind = kindIssuer
+		}
+		if kind == kindClusterIssuer && name != p.ClusterIssuerName && !p.isAllowedClusterIssuer(name) {
+			return nil, &tls.ProvisionError{
+				Reason: ReasonIssuerNotAllowed,
+				Err:    fmt.Errorf("cluster issuer '%s' is not allowed", name),
+			}
+		}
+	}
+
+	return map[string]interface{}{
+		"group": issuerGroup,
+		"kind":  kind,
+		"name":  name,
+	}, nil
+}
+
+func (p *Provider) isAllowedClusterIssuer(name string) bool {
+	for _, n := range p.AllowedClusterIssuers {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func newCertificate() *unstructured.Unstructured {
+	cert := &unstructured.Unstructured{}
+	cert.SetGroupVersionKind(CertificateGVK)
+	return cert
+}
+
+func isSameIssuer(a map[string]string, b map[string]interface{}) bool {
+	group := a["group"]
+	if group == "" {
+		group = issuerGroup
+	}
+	kind := a["kind"]
+	if kind == "" {
+		kind = kindIssuer
+	}
+	return group == b["group"] && kind == b["kind"] && a["name"] == b["name"]
+}
+
+func isCertificateReady(cert *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(cert.Object, "status", "conditions")
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if cond["type"] != "Ready" || cond["status"] != "True" {
+			continue
+		}
+		// Ready condition may be stale after spec changes (e.g. issuer
+		// changed) until cert-manager observes the new generation.
+		observedGeneration, found, _ := unstructured.NestedInt64(cond, "observedGeneration")
+		return !found || observedGeneration == cert.GetGeneration()
+	}
+	return false
+}
@@ -0,0 +1,156 @@
+package certmanager_test
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/tls"
+	"github.com/skygeario/k8s-controller/pkg/domain/tls/certmanager"
+)
+
+func newRegistration(issuer *domainv1beta1.CertIssuerReference) *domainv1beta1.CustomDomainRegistration {
+	return &domainv1beta1.CustomDomainRegistration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "www", UID: "www-uid"},
+		Spec: domainv1beta1.CustomDomainRegistrationSpec{
+			DomainName: "www.my-app.test",
+			DomainConfig: domainv1beta1.CustomDomainConfig{
+				CertIssuer: issuer,
+			},
+		},
+	}
+}
+
+func getIssuerRef(t *testing.T, kubeClient client.Client, reg *domainv1beta1.CustomDomainRegistration) map[string]string {
+	cert := &unstructured.Unstructured{}
+	cert.SetGroupVersionKind(certmanager.CertificateGVK)
+	if err := kubeClient.Get(context.Background(), types.NamespacedName{Namespace: reg.Namespace, Name: reg.Name}, cert); err != nil {
+		t.Fatal(err)
+	}
+	ref, _, err := unstructured.NestedStringMap(cert.Object, "spec", "issuerRef")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ref
+}
+
+func TestProvisionIssuer(t *testing.T) {
+	cases := []struct {
+		name   string
+		issuer *domainv1beta1.CertIssuerReference
+		kind   string
+		ref    string
+		reason string
+	}{
+		{"default issuer", nil, "ClusterIssuer", "letsencrypt", ""},
+		{"allowed cluster issuer", &domainv1beta1.CertIssuerReference{Kind: "ClusterIssuer", Name: "letsencrypt-staging"}, "ClusterIssuer", "letsencrypt-staging", ""},
+		{"namespace issuer", &domainv1beta1.CertIssuerReference{Name: "app-issuer"}, "Issuer", "app-issuer", ""},
+		{"disallowed cluster issuer", &domainv1beta1.CertIssuerReference{Kind: "ClusterIssuer", Name: "internal-ca"}, "", "", certmanager.ReasonIssuerNotAllowed},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			kubeClient := fake.NewFakeClientWithScheme(clientgoscheme.Scheme)
+			provider, err := certmanager.NewProvider(kubeClient, certmanager.Config{
+				ClusterIssuerName:     "letsencrypt",
+				AllowedClusterIssuers: []string{"letsencrypt-staging"},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			reg := newRegistration(c.issuer)
+			result, err := provider.Provision(context.Background(), reg)
+			if c.reason != "" {
+				if result != nil || tls.ReasonOf(err) != c.reason {
+					t.Fatalf("unexpected result: %#v, %v", result, err)
+				}
+				return
+			}
+			if result != nil || err != nil {
+				t.Fatalf("unexpected result: %#v, %v", result, err)
+			}
+
+			ref := getIssuerRef(t, kubeClient, reg)
+			if ref["group"] != "cert-manager.io" || ref["kind"] != c.kind || ref["name"] != c.ref {
+				t.Errorf("unexpected issuer: %v", ref)
+			}
+		})
+	}
+}
+
+func TestProvisionIssuerChange(t *testing.T) {
+	kubeClient := fake.NewFakeClientWithScheme(clientgoscheme.Scheme)
+	provider, err := certmanager.NewProvider(kubeClient, certmanager.Config{
+		ClusterIssuerName:     "letsencrypt",
+		AllowedClusterIssuers: []string{"letsencrypt-staging"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	reg := newRegistration(nil)
+
+	if _, err := provider.Provision(ctx, reg); err != nil {
+		t.Fatal(err)
+	}
+	cert := &unstructured.Unstructured{}
+	cert.SetGroupVersionKind(certmanager.CertificateGVK)
+	if err := kubeClient.Get(ctx, types.NamespacedName{Namespace: reg.Namespace, Name: reg.Name}, cert); err != nil {
+		t.Fatal(err)
+	}
+	setReady := func(generation int64) {
+		if err := kubeClient.Get(ctx, types.NamespacedName{Namespace: reg.Namespace, Name: reg.Name}, cert); err != nil {
+			t.Fatal(err)
+		}
+		cert.Object["status"] = map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready", "status": "True", "observedGeneration": generation},
+			},
+		}
+		if err := kubeClient.Update(ctx, cert); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cert.SetGeneration(1)
+	if err := kubeClient.Update(ctx, cert); err != nil {
+		t.Fatal(err)
+	}
+	setReady(1)
+
+	result, err := provider.Provision(ctx, reg)
+	if err != nil || result == nil || result.CertSecretName != "www-tls" {
+		t.Fatalf("unexpected result: %#v, %v", result, err)
+	}
+
+	// certificate is re-issued by the new issuer
+	reg.Spec.DomainConfig.CertIssuer = &domainv1beta1.CertIssuerReference{Kind: "ClusterIssuer", Name: "letsencrypt-staging"}
+	if result, err := provider.Provision(ctx, reg); err != nil || result != nil {
+		t.Fatalf("unexpected result: %#v, %v", result, err)
+	}
+	if ref := getIssuerRef(t, kubeClient, reg); ref["kind"] != "ClusterIssuer" || ref["name"] != "letsencrypt-staging" {
+		t.Errorf("unexpected issuer: %v", ref)
+	}
+
+	// stale ready condition is not trusted until new generation is observed
+	if err := kubeClient.Get(ctx, types.NamespacedName{Namespace: reg.Namespace, Name: reg.Name}, cert); err != nil {
+		t.Fatal(err)
+	}
+	cert.SetGeneration(2)
+	if err := kubeClient.Update(ctx, cert); err != nil {
+		t.Fatal(err)
+	}
+	if result, err := provider.Provision(ctx, reg); err != nil || result != nil {
+		t.Fatalf("unexpected result: %#v, %v", result, err)
+	}
+	setReady(2)
+	if result, err := provider.Provision(ctx, reg); err != nil || result == nil {
+		t.Fatalf("unexpected result: %#v, %v", result, err)
+	}
+}
@@ -0,0 +1,38 @@
+package tls
+
+import (
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultExpiryThresholds are the default remaining validity periods at which
+// expiring certificates are alerted.
+var DefaultExpiryThresholds = []time.Duration{
+	30 * 24 * time.Hour,
+	7 * 24 * time.Hour,
+	24 * time.Hour,
+}
+
+type ExpiryConfig struct {
+	// Thresholds are the remaining validity periods at which expiring
+	// certificates are alerted, defaults to 720h, 168h and 24h.
+	Thresholds []metav1.Duration
+}
+
+// ExpiryThresholds returns the alert thresholds in descending order.
+func ExpiryThresholds(config *ExpiryConfig) []time.Duration {
+	if config == nil || len(config.Thresholds) == 0 {
+		return DefaultExpiryThresholds
+	}
+
+	thresholds := make([]time.Duration, len(config.Thresholds))
+	for i, d := range config.Thresholds {
+		thresholds[i] = d.Duration
+	}
+	sort.Slice(thresholds, func(i, j int) bool {
+		return thresholds[i] > thresholds[j]
+	})
+	return thresholds
+}
@@ -2,6 +2,8 @@ package tls
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
 )
@@ -13,4 +15,31 @@ type Provider interface {
 
 type ProvisionResult struct {
 	CertSecretName string
+	// RequeueTime is the time the provider should be consulted again, e.g.
+	// to renew the certificate.
+	RequeueTime *time.Time
+}
+
+// ProvisionError is an error with reason to be reported in certificate
+// condition.
+type ProvisionError struct {
+	Reason string
+	Err    error
+}
+
+func (e *ProvisionError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ProvisionError) Unwrap() error {
+	return e.Err
+}
+
+// ReasonOf returns the condition reason of err, or empty if not available.
+func ReasonOf(err error) string {
+	var perr *ProvisionError
+	if errors.As(err, &perr) {
+		return perr.Reason
+	}
+	return ""
 }
@@ -2,12 +2,18 @@ package usersecret
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
-	"github.com/skygeario/k8s-controller/pkg/domain/tls"
+	domaintls "github.com/skygeario/k8s-controller/pkg/domain/tls"
 )
 
 var scheme = runtime.NewScheme()
@@ -16,22 +22,50 @@ func init() {
 	_ = domainv1beta1.AddToScheme(scheme)
 }
 
+const (
+	ReasonSecretNotFound         = "SecretNotFound"
+	ReasonInvalidSecret          = "InvalidSecret"
+	ReasonInvalidCertificate     = "InvalidCertificate"
+	ReasonKeyMismatch            = "KeyMismatch"
+	ReasonDomainNotCovered       = "DomainNotCovered"
+	ReasonCertificateNotYetValid = "CertificateNotYetValid"
+	ReasonCertificateExpired     = "CertificateExpired"
+)
+
 type Provider struct {
+	KubeClient client.Client
+	Now        func() time.Time
 }
 
-func NewProvider() (*Provider, error) {
-	return &Provider{}, nil
+func NewProvider(client client.Client) (*Provider, error) {
+	return &Provider{
+		KubeClient: client,
+		Now:        time.Now,
+	}, nil
 }
 
-var _ tls.Provider = &Provider{}
+var _ domaintls.Provider = &Provider{}
 
-func (p *Provider) Provision(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (*tls.ProvisionResult, error) {
+func (p *Provider) Provision(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (*domaintls.ProvisionResult, error) {
 	if reg.Spec.DomainConfig.CertSecretName == nil {
 		return nil, fmt.Errorf("certificate secret name not provided")
 	}
+	secretName := *reg.Spec.DomainConfig.CertSecretName
+
+	var secret corev1.Secret
+	if err := p.KubeClient.Get(ctx, types.NamespacedName{Namespace: reg.Namespace, Name: secretName}, &secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, provisionError(ReasonSecretNotFound, "certificate secret '%s' not found", secretName)
+		}
+		return nil, err
+	}
+
+	if err := p.validateSecret(&secret, reg.Spec.DomainName); err != nil {
+		return nil, err
+	}
 
-	return &tls.ProvisionResult{
-		CertSecretName: *reg.Spec.DomainConfig.CertSecretName,
+	return &domaintls.ProvisionResult{
+		CertSecretName: secretName,
 	}, nil
 }
 
@@ -39,3 +73,44 @@ func (p *Provider) Release(ctx context.Context, reg *domainv1beta1.CustomDomainR
 	// Nothing to do here.
 	return true, nil
 }
+
+func (p *Provider) validateSecret(secret *corev1.Secret, domainName string) error {
+	if secret.Type != corev1.SecretTypeTLS {
+		return provisionError(ReasonInvalidSecret, "certificate secret must be of type '%s'", corev1.SecretTypeTLS)
+	}
+	certPEM := secret.Data[corev1.TLSCertKey]
+	keyPEM := secret.Data[corev1.TLSPrivateKeyKey]
+	if len(certPEM) == 0 || len(keyPEM) == 0 {
+		return provisionError(ReasonInvalidSecret, "certificate secret must contain '%s' and '%s'", corev1.TLSCertKey, corev1.TLSPrivateKeyKey)
+	}
+
+	chain, err := domaintls.ParseCertificateChain(certPEM)
+	if err != nil {
+		return provisionError(ReasonInvalidCertificate, "invalid certificate: %s", err)
+	}
+	if _, err := tls.X509KeyPair(certPEM, keyPEM); err != nil {
+		return provisionError(ReasonKeyMismatch, "private key does not match certificate: %s", err)
+	}
+
+	leaf := chain[0]
+	if err := leaf.VerifyHostname(domainName); err != nil {
+		return provisionError(ReasonDomainNotCovered, "certificate does not cover domain '%s'", domainName)
+	}
+
+	now := p.Now()
+	if now.Before(leaf.NotBefore) {
+		return provisionError(ReasonCertificateNotYetValid, "certificate is not valid before %s", leaf.NotBefore.UTC().Format(time.RFC3339))
+	}
+	if now.After(leaf.NotAfter) {
+		return provisionError(ReasonCertificateExpired, "certificate is expired at %s", leaf.NotAfter.UTC().Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+func provisionError(reason string, format string, args ...interface{}) error {
+	return &domaintls.ProvisionError{
+		Reason: reason,
+		Err:    fmt.Errorf(format, args...),
+	}
+}
@@ -0,0 +1,126 @@
+package usersecret_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/tls"
+	"github.com/skygeario/k8s-controller/pkg/domain/tls/usersecret"
+)
+
+var now = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func makeKeyPair(t *testing.T, dnsNames []string, notBefore, notAfter time.Time) (certPEM []byte, keyPEM []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: dnsNames[0]},
+		DNSNames:     dnsNames,
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return
+}
+
+func makeSecret(name string, certPEM []byte, keyPEM []byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: name},
+		Type:       corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       certPEM,
+			corev1.TLSPrivateKeyKey: keyPEM,
+		},
+	}
+}
+
+func TestProvision(t *testing.T) {
+	validCert, validKey := makeKeyPair(t, []string{"*.my-app.test"}, now.Add(-time.Hour), now.Add(time.Hour))
+	_, otherKey := makeKeyPair(t, []string{"*.my-app.test"}, now.Add(-time.Hour), now.Add(time.Hour))
+	otherCert, otherCertKey := makeKeyPair(t, []string{"other-app.test"}, now.Add(-time.Hour), now.Add(time.Hour))
+	expiredCert, expiredKey := makeKeyPair(t, []string{"www.my-app.test"}, now.Add(-2*time.Hour), now.Add(-time.Hour))
+	futureCert, futureKey := makeKeyPair(t, []string{"www.my-app.test"}, now.Add(time.Hour), now.Add(2*time.Hour))
+
+	opaque := makeSecret("opaque", validCert, validKey)
+	opaque.Type = corev1.SecretTypeOpaque
+
+	kubeClient := fake.NewFakeClientWithScheme(clientgoscheme.Scheme,
+		makeSecret("valid", validCert, validKey),
+		makeSecret("mismatch", validCert, otherKey),
+		makeSecret("other", otherCert, otherCertKey),
+		makeSecret("expired", expiredCert, expiredKey),
+		makeSecret("future", futureCert, futureKey),
+		makeSecret("invalid", []byte("invalid"), validKey),
+		opaque,
+	)
+	provider, err := usersecret.NewProvider(kubeClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider.Now = func() time.Time { return now }
+
+	cases := []struct {
+		secretName string
+		reason     string
+	}{
+		{"valid", ""},
+		{"missing", usersecret.ReasonSecretNotFound},
+		{"opaque", usersecret.ReasonInvalidSecret},
+		{"invalid", usersecret.ReasonInvalidCertificate},
+		{"mismatch", usersecret.ReasonKeyMismatch},
+		{"other", usersecret.ReasonDomainNotCovered},
+		{"expired", usersecret.ReasonCertificateExpired},
+		{"future", usersecret.ReasonCertificateNotYetValid},
+	}
+	for _, c := range cases {
+		reg := &domainv1beta1.CustomDomainRegistration{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "www.my-app.test"},
+			Spec: domainv1beta1.CustomDomainRegistrationSpec{
+				DomainName: "www.my-app.test",
+				DomainConfig: domainv1beta1.CustomDomainConfig{
+					CertSecretName: pointer.StringPtr(c.secretName),
+				},
+			},
+		}
+
+		result, err := provider.Provision(context.Background(), reg)
+		if c.reason == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %s", c.secretName, err)
+			} else if result == nil || result.CertSecretName != c.secretName {
+				t.Errorf("%s: unexpected result: %v", c.secretName, result)
+			}
+			continue
+		}
+		if reason := tls.ReasonOf(err); reason != c.reason {
+			t.Errorf("%s: expected reason %s, got %s (%v)", c.secretName, c.reason, reason, err)
+		}
+	}
+}
@@ -3,24 +3,22 @@ package verification
 import (
 	"context"
 	"fmt"
-	"net"
 )
 
-var resolver = &net.Resolver{}
-
-func VerifyDomain(ctx context.Context, domain string, token string) error {
-	recordName, err := MakeDNSRecordName(domain)
-	if err != nil {
-		return fmt.Errorf("cannot lookup verification DNS record: %w", err)
+func VerifyDomain(ctx context.Context, resolver *Resolver, format RecordFormat, recordDomain string, token string) error {
+	if resolver == nil {
+		resolver = defaultResolver
 	}
 
+	recordName := format.MakeRecordName(recordDomain)
 	records, err := resolver.LookupTXT(ctx, recordName)
 	if err != nil {
 		return fmt.Errorf("cannot lookup verification DNS record: %w", err)
 	}
 
+	expectedValue := format.MakeRecordValue(token)
 	for _, value := range records {
-		if value == token {
+		if value == expectedValue {
 			return nil
 		}
 	}
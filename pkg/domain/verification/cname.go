@@ -0,0 +1,41 @@
+package verification
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// CNAMETokenLength is length of token used in CNAME record name, since a DNS
+// label cannot be longer than 63 characters.
+const CNAMETokenLength = 32
+
+// MakeCNAMERecordName returns the name of verification CNAME record placed
+// under recordDomain, see RecordDomain.
+func MakeCNAMERecordName(recordDomain string, token string) string {
+	if len(token) > CNAMETokenLength {
+		token = token[:CNAMETokenLength]
+	}
+	return fmt.Sprintf("%s.%s", token, recordDomain)
+}
+
+func VerifyCNAME(ctx context.Context, resolver *Resolver, recordDomain string, token string, target string) error {
+	if resolver == nil {
+		resolver = defaultResolver
+	}
+
+	recordName := MakeCNAMERecordName(recordDomain, token)
+	cname, err := resolver.LookupCNAME(ctx, recordName)
+	if err != nil {
+		return fmt.Errorf("cannot lookup verification DNS record: %w", err)
+	}
+
+	if normalizeDomain(cname) != normalizeDomain(target) {
+		return fmt.Errorf("verification DNS record not found")
+	}
+	return nil
+}
+
+func normalizeDomain(domain string) string {
+	return strings.TrimSuffix(strings.ToLower(domain), ".")
+}
@@ -0,0 +1,45 @@
+package verification_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/skygeario/k8s-controller/pkg/domain/verification"
+)
+
+func TestVerifyCNAME(t *testing.T) {
+	const target = "verify.example.test"
+	recordName := verification.MakeCNAMERecordName("my-app.test", testToken)
+
+	cases := []struct {
+		name    string
+		records map[string]string
+		ok      bool
+	}{
+		{"direct", map[string]string{recordName: target}, true},
+		{"chained", map[string]string{recordName: "proxy.attacker.test", "proxy.attacker.test": target}, false},
+		{"other target", map[string]string{recordName: "other.example.test"}, false},
+		{"missing", map[string]string{}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server := newDNSServer(t)
+			defer server.Close()
+			server.SetRecords("my-app.test", "A", "10.0.0.1")
+			server.SetRecords(target, "A", "10.0.0.2")
+			for name, value := range c.records {
+				server.SetRecords(name, "CNAME", value)
+			}
+
+			resolver := &verification.Resolver{
+				Nameservers: []string{server.Addr},
+				Timeout:     time.Second,
+			}
+			err := verification.VerifyCNAME(context.Background(), resolver, "my-app.test", testToken, target)
+			if (err == nil) != c.ok {
+				t.Errorf("unexpected verification result: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package verification
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type Config struct {
+	ReverifyInterval *metav1.Duration
+	GracePeriod      *metav1.Duration
+	HTTP             *HTTPConfig
+	CNAME            *CNAMEConfig
+	Resolver         *ResolverConfig
+	// RecordPrefix is the prefix of verification TXT record name, defaults
+	// to _skygear.
+	RecordPrefix string
+	// TokenKey is the key of verification TXT record value in key=value
+	// format, e.g. skygear-verification; token is used directly if empty.
+	TokenKey string
+	// DisallowParentZoneRecords requires verification DNS records of
+	// subdomains to be placed under the exact domain, instead of the
+	// registrable root domain.
+	DisallowParentZoneRecords bool
+	// KeyRotationPeriod is the period that previous verification key is
+	// still accepted after rotation, defaults to 7 days.
+	KeyRotationPeriod *metav1.Duration
+}
+
+type HTTPConfig struct {
+	// ListenAddress is the address the token server binds to.
+	ListenAddress string
+	// ServiceName is the name of Service exposing the token server.
+	ServiceName string
+	// ServicePort is the port of Service exposing the token server.
+	ServicePort int
+	// Namespace is the namespace of the Service, where verification Ingresses are created.
+	Namespace string
+}
+
+type CNAMEConfig struct {
+	// Target is the host name verification CNAME records should point to.
+	Target string
+}
+
+type ResolverConfig struct {
+	// Nameservers are addresses of upstream nameservers, system resolver is
+	// used if empty.
+	Nameservers []string
+	// QueryAuthoritative indicates whether to query authoritative
+	// nameservers of the zone directly.
+	QueryAuthoritative bool
+	// Timeout is the timeout of each DNS query.
+	Timeout *metav1.Duration
+	// DNSSEC is the DNSSEC validation mode of verification lookups, requires
+	// validating upstream nameservers.
+	DNSSEC DNSSECMode
+	// Quorum is the number of nameservers required to agree on verification,
+	// nameservers are queried independently if set. It cannot be used with
+	// QueryAuthoritative.
+	Quorum int
+}
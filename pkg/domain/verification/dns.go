@@ -2,14 +2,43 @@ package verification
 
 import (
 	"fmt"
-
-	"golang.org/x/net/publicsuffix"
+	"regexp"
 )
 
-func MakeDNSRecordName(domain string) (string, error) {
-	rootDomain, err := publicsuffix.EffectiveTLDPlusOne(domain)
-	if err != nil {
-		return "", err
+const DefaultRecordPrefix = "_skygear"
+
+var recordPrefixPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+(\.[A-Za-z0-9_-]+)*$`)
+
+// RecordFormat is the format of verification TXT record, zero value is the
+// default format.
+type RecordFormat struct {
+	// Prefix is the prefix of record name, defaults to _skygear.
+	Prefix string
+	// TokenKey is the key of record value in key=value format, token is
+	// used as value directly if empty.
+	TokenKey string
+}
+
+func (f RecordFormat) Validate() error {
+	if f.Prefix != "" && !recordPrefixPattern.MatchString(f.Prefix) {
+		return fmt.Errorf("invalid verification record prefix '%s'", f.Prefix)
+	}
+	return nil
+}
+
+// MakeRecordName returns the name of verification TXT record placed under
+// recordDomain, see RecordDomain.
+func (f RecordFormat) MakeRecordName(recordDomain string) string {
+	prefix := f.Prefix
+	if prefix == "" {
+		prefix = DefaultRecordPrefix
+	}
+	return fmt.Sprintf("%s.%s", prefix, recordDomain)
+}
+
+func (f RecordFormat) MakeRecordValue(token string) string {
+	if f.TokenKey == "" {
+		return token
 	}
-	return fmt.Sprintf("_skygear.%s", rootDomain), nil
+	return fmt.Sprintf("%s=%s", f.TokenKey, token)
 }
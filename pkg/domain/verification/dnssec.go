@@ -0,0 +1,12 @@
+package verification
+
+type DNSSECMode string
+
+const (
+	// DNSSECModeNone performs no DNSSEC validation.
+	DNSSECModeNone DNSSECMode = ""
+	// DNSSECModeValidate rejects answers of signed zones failing validation.
+	DNSSECModeValidate DNSSECMode = "validate"
+	// DNSSECModeRequire rejects answers not validated by the resolver.
+	DNSSECModeRequire DNSSECMode = "require"
+)
@@ -0,0 +1,58 @@
+package verification_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/skygeario/k8s-controller/pkg/domain/verification"
+)
+
+func TestVerifyDomainWithDNSSEC(t *testing.T) {
+	server := newDNSServer(t)
+	defer server.Close()
+	server.SetRecords("_skygear.my-app.test", "TXT", testToken)
+	server.SetRecords("_skygear.signed.test", "TXT", testToken)
+	server.SetBogus("_skygear.signed.test", true)
+
+	newResolver := func(mode verification.DNSSECMode) *verification.Resolver {
+		resolver, err := verification.NewResolver(verification.ResolverConfig{
+			Nameservers: []string{server.Addr},
+			DNSSEC:      mode,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		resolver.Timeout = time.Second
+		return resolver
+	}
+	ctx := context.Background()
+
+	validate := newResolver(verification.DNSSECModeValidate)
+	if err := verification.VerifyDomain(ctx, validate, verification.RecordFormat{}, "my-app.test", testToken); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	err := verification.VerifyDomain(ctx, validate, verification.RecordFormat{}, "signed.test", testToken)
+	if err == nil || err.Error() != "cannot lookup verification DNS record: DNSSEC validation failed for _skygear.signed.test" {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := verification.VerifyDomain(ctx, validate, verification.RecordFormat{}, "unknown.test", testToken); err == nil {
+		t.Errorf("expected lookup error")
+	}
+
+	require := newResolver(verification.DNSSECModeRequire)
+	if err := verification.VerifyDomain(ctx, require, verification.RecordFormat{}, "my-app.test", testToken); err == nil {
+		t.Errorf("expected records not validated")
+	}
+	server.SetAuthenticData(true)
+	if err := verification.VerifyDomain(ctx, require, verification.RecordFormat{}, "my-app.test", testToken); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if err := verification.VerifyDomain(ctx, require, verification.RecordFormat{}, "my-app.test", "invalid-token"); err == nil {
+		t.Errorf("expected record not found")
+	}
+
+	if _, err := verification.NewResolver(verification.ResolverConfig{DNSSEC: verification.DNSSECModeValidate}); err == nil {
+		t.Errorf("expected nameservers required")
+	}
+}
@@ -0,0 +1,51 @@
+package verification
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+const HTTPPathPrefix = "/.well-known/skygear-domain-verification/"
+
+const maxHTTPTokenSize = 1024
+
+var httpClient = &http.Client{}
+
+func MakeHTTPPath(nonce string) string {
+	return HTTPPathPrefix + nonce
+}
+
+func MakeHTTPURL(domain string, nonce string) string {
+	return fmt.Sprintf("http://%s%s", domain, MakeHTTPPath(nonce))
+}
+
+func VerifyHTTP(ctx context.Context, domain string, nonce string, token string) error {
+	req, err := http.NewRequest("GET", MakeHTTPURL(domain, nonce), nil)
+	if err != nil {
+		return fmt.Errorf("cannot fetch verification URL: %w", err)
+	}
+
+	resp, err := httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("cannot fetch verification URL: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("verification URL returned status %d", resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxHTTPTokenSize))
+	if err != nil {
+		return fmt.Errorf("cannot fetch verification URL: %w", err)
+	}
+
+	if strings.TrimSpace(string(body)) != token {
+		return fmt.Errorf("verification token not found")
+	}
+	return nil
+}
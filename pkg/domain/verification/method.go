@@ -0,0 +1,12 @@
+package verification
+
+import (
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+)
+
+func MethodOf(reg *domainv1beta1.CustomDomainRegistration) domainv1beta1.VerificationMethod {
+	if reg.Spec.VerificationMethod == nil {
+		return domainv1beta1.VerificationMethodTXT
+	}
+	return *reg.Spec.VerificationMethod
+}
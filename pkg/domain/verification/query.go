@@ -0,0 +1,136 @@
+package verification
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+const maxUDPPayloadSize = 4096
+
+func (r *Resolver) lookupTXTRaw(ctx context.Context, name string) ([]string, error) {
+	answers, err := r.lookupRaw(ctx, name, dns.TypeTXT)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []string
+	for _, answer := range answers {
+		if txt, ok := answer.(*dns.TXT); ok {
+			records = append(records, strings.Join(txt.Txt, ""))
+		}
+	}
+	return records, nil
+}
+
+func (r *Resolver) lookupCNAMERaw(ctx context.Context, name string) (string, error) {
+	answers, err := r.lookupRaw(ctx, name, dns.TypeCNAME)
+	if err != nil {
+		return "", err
+	}
+
+	for _, answer := range answers {
+		if cname, ok := answer.(*dns.CNAME); ok {
+			return cname.Target, nil
+		}
+	}
+	return name, nil
+}
+
+// lookupRaw queries the nameservers for records of the exact name and type
+// without following CNAME records, rejecting answers failing DNSSEC
+// validation if enabled.
+func (r *Resolver) lookupRaw(ctx context.Context, name string, qtype uint16) ([]dns.RR, error) {
+	nameservers, err := r.queryNameservers(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	fqdn := dns.Fqdn(name)
+
+	var lastErr error
+	for _, server := range nameservers {
+		resp, err := r.exchange(ctx, server, fqdn, qtype, false)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		switch resp.Rcode {
+		case dns.RcodeSuccess:
+		case dns.RcodeNameError:
+			return nil, &net.DNSError{Err: "no such host", Name: name, Server: server, IsNotFound: true}
+		case dns.RcodeServerFailure:
+			// Validating resolvers fail bogus answers, unless checking is disabled.
+			if r.DNSSEC != DNSSECModeNone {
+				cdResp, err := r.exchange(ctx, server, fqdn, qtype, true)
+				if err == nil && cdResp.Rcode != dns.RcodeServerFailure {
+					return nil, fmt.Errorf("DNSSEC validation failed for %s", name)
+				}
+			}
+			lastErr = &net.DNSError{Err: "server misbehaving", Name: name, Server: server}
+			continue
+		default:
+			lastErr = &net.DNSError{Err: fmt.Sprintf("unexpected response code %s", dns.RcodeToString[resp.Rcode]), Name: name, Server: server}
+			continue
+		}
+
+		if r.DNSSEC == DNSSECModeRequire && !resp.AuthenticatedData {
+			return nil, fmt.Errorf("DNS records of %s are not DNSSEC validated", name)
+		}
+
+		var answers []dns.RR
+		for _, answer := range resp.Answer {
+			h := answer.Header()
+			if h.Rrtype == qtype && strings.EqualFold(h.Name, fqdn) {
+				answers = append(answers, answer)
+			}
+		}
+		return answers, nil
+	}
+	return nil, lastErr
+}
+
+func (r *Resolver) exchange(ctx context.Context, server string, fqdn string, qtype uint16, checkingDisabled bool) (*dns.Msg, error) {
+	msg := new(dns.Msg)
+	msg.SetQuestion(fqdn, qtype)
+	msg.SetEdns0(maxUDPPayloadSize, true)
+	msg.AuthenticatedData = true
+	msg.CheckingDisabled = checkingDisabled
+
+	var timeout time.Duration
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	client := &dns.Client{
+		UDPSize: maxUDPPayloadSize,
+		Timeout: timeout,
+		// Authoritative nameservers are addressed by host names.
+		Dialer: &net.Dialer{Timeout: timeout, Resolver: r.upstream()},
+	}
+
+	resp, _, err := client.Exchange(msg, server)
+	if err == nil && resp.Truncated {
+		client.Net = "tcp"
+		resp, _, err = client.Exchange(msg, server)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Message ID is checked by the client, also check the question to
+	// reject spoofed responses to other queries.
+	if !resp.Response || len(resp.Question) != 1 ||
+		!strings.EqualFold(resp.Question[0].Name, fqdn) ||
+		resp.Question[0].Qtype != qtype || resp.Question[0].Qclass != dns.ClassINET {
+		return nil, &net.DNSError{Err: "invalid response", Name: fqdn, Server: server}
+	}
+	return resp, nil
+}
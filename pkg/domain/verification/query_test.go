@@ -0,0 +1,65 @@
+package verification_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+
+	"github.com/skygeario/k8s-controller/pkg/domain/verification"
+)
+
+func TestLookupRejectsMismatchedQuestion(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// Respond with an answer to another question, using the query ID.
+	go func() {
+		buf := make([]byte, 65535)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var req dns.Msg
+			if err := req.Unpack(buf[:n]); err != nil {
+				continue
+			}
+			var question dns.Msg
+			question.SetQuestion("_skygear.other-app.test.", dns.TypeTXT)
+			resp := new(dns.Msg)
+			resp.SetReply(&question)
+			resp.Id = req.Id
+			resp.Answer = append(resp.Answer, &dns.TXT{
+				Hdr: dns.RR_Header{Name: "_skygear.other-app.test.", Rrtype: dns.TypeTXT, Class: dns.ClassINET},
+				Txt: []string{testToken},
+			})
+			out, err := resp.Pack()
+			if err != nil {
+				continue
+			}
+			_, _ = conn.WriteTo(out, addr)
+		}
+	}()
+
+	resolver, err := verification.NewResolver(verification.ResolverConfig{
+		Nameservers: []string{conn.LocalAddr().String()},
+		DNSSEC:      verification.DNSSECModeValidate,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolver.Timeout = time.Second
+
+	if _, err := resolver.LookupTXT(context.Background(), "_skygear.my-app.test"); err == nil {
+		t.Errorf("expected response with mismatched question to be rejected")
+	}
+	if _, err := resolver.LookupCNAME(context.Background(), "_skygear.my-app.test"); err == nil {
+		t.Errorf("expected response with mismatched question to be rejected")
+	}
+}
@@ -0,0 +1,73 @@
+package verification
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Quorum performs verification using multiple resolvers, succeeding only if
+// enough resolvers agree.
+type Quorum struct {
+	Resolvers []*Resolver
+	Size      int
+}
+
+type QuorumResult struct {
+	Resolver string
+	Err      error
+}
+
+func NewQuorum(config ResolverConfig) (*Quorum, error) {
+	if config.Quorum > len(config.Nameservers) {
+		return nil, fmt.Errorf("quorum size %d is larger than number of nameservers", config.Quorum)
+	}
+	if config.QueryAuthoritative {
+		// Members would query the same authoritative nameservers, which
+		// defeats the purpose of having a quorum.
+		return nil, fmt.Errorf("quorum cannot be used with authoritative queries")
+	}
+
+	resolvers := make([]*Resolver, len(config.Nameservers))
+	for i, ns := range config.Nameservers {
+		c := config
+		c.Nameservers = []string{ns}
+		r, err := NewResolver(c)
+		if err != nil {
+			return nil, err
+		}
+		resolvers[i] = r
+	}
+
+	return &Quorum{
+		Resolvers: resolvers,
+		Size:      config.Quorum,
+	}, nil
+}
+
+func (q *Quorum) Verify(ctx context.Context, verify func(ctx context.Context, resolver *Resolver) error) ([]QuorumResult, error) {
+	results := make([]QuorumResult, len(q.Resolvers))
+	var wg sync.WaitGroup
+	for i, r := range q.Resolvers {
+		wg.Add(1)
+		go func(i int, r *Resolver) {
+			defer wg.Done()
+			results[i] = QuorumResult{Resolver: r.String(), Err: verify(ctx, r)}
+		}(i, r)
+	}
+	wg.Wait()
+
+	agreed := 0
+	var lastErr error
+	for _, result := range results {
+		if result.Err == nil {
+			agreed++
+		} else {
+			lastErr = result.Err
+		}
+	}
+	if agreed >= q.Size {
+		return results, nil
+	}
+	return results, fmt.Errorf("verification quorum not reached (%d of %d required): %w", agreed, q.Size, lastErr)
+}
@@ -0,0 +1,88 @@
+package verification_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/skygeario/k8s-controller/pkg/domain/verification"
+)
+
+func TestQuorum(t *testing.T) {
+	var nameservers []string
+	for _, token := range []string{testToken, testToken, "poisoned-token"} {
+		server := newDNSServer(t)
+		defer server.Close()
+		server.SetRecords("_skygear.my-app.test", "TXT", token)
+		nameservers = append(nameservers, server.Addr)
+	}
+
+	verify := func(ctx context.Context, resolver *verification.Resolver) error {
+		return verification.VerifyDomain(ctx, resolver, verification.RecordFormat{}, "my-app.test", testToken)
+	}
+
+	quorum, err := verification.NewQuorum(verification.ResolverConfig{Nameservers: nameservers, Quorum: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := quorum.Verify(context.Background(), verify)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("unexpected results: %#v", results)
+	}
+	for i, r := range results {
+		if r.Resolver != nameservers[i] {
+			t.Errorf("unexpected resolver: %s", r.Resolver)
+		}
+		if (r.Err == nil) != (i != 2) {
+			t.Errorf("unexpected result of resolver %s: %v", r.Resolver, r.Err)
+		}
+	}
+
+	quorum.Size = 3
+	if _, err := quorum.Verify(context.Background(), verify); err == nil {
+		t.Errorf("expected quorum not reached")
+	}
+
+	if _, err := verification.NewQuorum(verification.ResolverConfig{Nameservers: nameservers, Quorum: 4}); err == nil {
+		t.Errorf("expected invalid quorum size")
+	}
+}
+
+func TestQuorumMembersIndependent(t *testing.T) {
+	var nameservers []string
+	var tokens []string
+	for _, token := range []string{"token-1", "token-2", "token-3"} {
+		server := newDNSServer(t)
+		defer server.Close()
+		server.SetRecords("_skygear.my-app.test", "TXT", token)
+		nameservers = append(nameservers, server.Addr)
+		tokens = append(tokens, token)
+	}
+
+	quorum, err := verification.NewQuorum(verification.ResolverConfig{Nameservers: nameservers, Quorum: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each member should see only the records of its own nameserver.
+	for i, token := range tokens {
+		results, err := quorum.Verify(context.Background(), func(ctx context.Context, resolver *verification.Resolver) error {
+			return verification.VerifyDomain(ctx, resolver, verification.RecordFormat{}, "my-app.test", token)
+		})
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+		for j, r := range results {
+			if (r.Err == nil) != (i == j) {
+				t.Errorf("unexpected result of resolver %s for %s: %v", r.Resolver, token, r.Err)
+			}
+		}
+	}
+
+	_, err = verification.NewQuorum(verification.ResolverConfig{Nameservers: nameservers, Quorum: 2, QueryAuthoritative: true})
+	if err == nil {
+		t.Errorf("expected quorum with authoritative queries to be rejected")
+	}
+}
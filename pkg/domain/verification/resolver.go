@@ -0,0 +1,204 @@
+package verification
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/miekg/dns"
+	"golang.org/x/net/publicsuffix"
+)
+
+const DefaultQueryTimeout = 5 * time.Second
+
+const systemResolvConf = "/etc/resolv.conf"
+
+// Resolver performs DNS lookups for domain verification.
+type Resolver struct {
+	// Nameservers are addresses of upstream nameservers, system resolver is
+	// used if empty.
+	Nameservers []string
+	// QueryAuthoritative indicates whether to query authoritative
+	// nameservers of the zone directly, bypassing caches of upstream.
+	QueryAuthoritative bool
+	// AuthoritativePort is the port of authoritative nameservers.
+	AuthoritativePort string
+	// Timeout is the timeout of each DNS query.
+	Timeout time.Duration
+	// DNSSEC is the DNSSEC validation mode of verification lookups.
+	DNSSEC DNSSECMode
+}
+
+func NewResolver(config ResolverConfig) (*Resolver, error) {
+	nameservers := make([]string, len(config.Nameservers))
+	for i, ns := range config.Nameservers {
+		if _, _, err := net.SplitHostPort(ns); err != nil {
+			ns = net.JoinHostPort(ns, "53")
+		}
+		nameservers[i] = ns
+	}
+
+	timeout := DefaultQueryTimeout
+	if config.Timeout != nil {
+		timeout = config.Timeout.Duration
+	}
+
+	switch config.DNSSEC {
+	case DNSSECModeNone:
+	case DNSSECModeValidate, DNSSECModeRequire:
+		// Authoritative nameservers do not validate answers.
+		if len(nameservers) == 0 || config.QueryAuthoritative {
+			return nil, fmt.Errorf("DNSSEC validation requires validating upstream nameservers")
+		}
+	default:
+		return nil, fmt.Errorf("unknown DNSSEC mode '%s'", config.DNSSEC)
+	}
+
+	return &Resolver{
+		Nameservers:        nameservers,
+		QueryAuthoritative: config.QueryAuthoritative,
+		AuthoritativePort:  "53",
+		Timeout:            timeout,
+		DNSSEC:             config.DNSSEC,
+	}, nil
+}
+
+var defaultResolver = &Resolver{Timeout: DefaultQueryTimeout}
+
+func (r *Resolver) String() string {
+	if len(r.Nameservers) == 0 {
+		return "system"
+	}
+	return strings.Join(r.Nameservers, ",")
+}
+
+func (r *Resolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	if r.DNSSEC != DNSSECModeNone {
+		return r.lookupTXTRaw(ctx, name)
+	}
+
+	resolver, err := r.resolverFor(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	return resolver.LookupTXT(ctx, name)
+}
+
+// LookupCNAME returns the target of CNAME record of the name, or the name
+// itself if it has none. Unlike net.Resolver, only the first CNAME record is
+// looked up, since the rest of a CNAME chain is not controlled by owner of
+// the name.
+func (r *Resolver) LookupCNAME(ctx context.Context, name string) (string, error) {
+	return r.lookupCNAMERaw(ctx, name)
+}
+
+func (r *Resolver) LookupIPAddr(ctx context.Context, name string) ([]net.IPAddr, error) {
+	resolver, err := r.resolverFor(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	return resolver.LookupIPAddr(ctx, name)
+}
+
+func (r *Resolver) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, r.Timeout)
+}
+
+func (r *Resolver) resolverFor(ctx context.Context, name string) (*net.Resolver, error) {
+	upstream := r.upstream()
+	if !r.QueryAuthoritative {
+		return upstream, nil
+	}
+
+	nameservers, err := r.lookupAuthoritative(ctx, upstream, name)
+	if err != nil {
+		return nil, err
+	}
+	return r.makeResolver(nameservers, upstream), nil
+}
+
+// queryNameservers returns addresses of nameservers to send raw queries for
+// the name to.
+func (r *Resolver) queryNameservers(ctx context.Context, name string) ([]string, error) {
+	if r.QueryAuthoritative {
+		return r.lookupAuthoritative(ctx, r.upstream(), name)
+	}
+	if len(r.Nameservers) > 0 {
+		return r.Nameservers, nil
+	}
+
+	config, err := dns.ClientConfigFromFile(systemResolvConf)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read system resolver config: %w", err)
+	}
+	nameservers := make([]string, len(config.Servers))
+	for i, ns := range config.Servers {
+		nameservers[i] = net.JoinHostPort(ns, config.Port)
+	}
+	return nameservers, nil
+}
+
+func (r *Resolver) upstream() *net.Resolver {
+	if len(r.Nameservers) == 0 {
+		return net.DefaultResolver
+	}
+	return r.makeResolver(r.Nameservers, nil)
+}
+
+func (r *Resolver) lookupAuthoritative(ctx context.Context, upstream *net.Resolver, name string) ([]string, error) {
+	name = normalizeDomain(name)
+	rootDomain, err := publicsuffix.EffectiveTLDPlusOne(name)
+	if err != nil {
+		rootDomain = ""
+	}
+
+	zone := name
+	for {
+		nss, err := func() ([]*net.NS, error) {
+			ctx, cancel := r.withTimeout(ctx)
+			defer cancel()
+			return upstream.LookupNS(ctx, zone)
+		}()
+		if err == nil && len(nss) > 0 {
+			port := r.AuthoritativePort
+			if port == "" {
+				port = "53"
+			}
+			nameservers := make([]string, len(nss))
+			for i, ns := range nss {
+				nameservers[i] = net.JoinHostPort(normalizeDomain(ns.Host), port)
+			}
+			return nameservers, nil
+		}
+
+		i := strings.Index(zone, ".")
+		if zone == rootDomain || i < 0 {
+			return nil, fmt.Errorf("cannot find authoritative nameservers of %s", name)
+		}
+		zone = zone[i+1:]
+	}
+}
+
+func (r *Resolver) makeResolver(nameservers []string, dialResolver *net.Resolver) *net.Resolver {
+	var next uint32
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			i := atomic.AddUint32(&next, 1) - 1
+			d := net.Dialer{Timeout: r.Timeout, Resolver: dialResolver}
+			return d.DialContext(ctx, network, nameservers[int(i)%len(nameservers)])
+		},
+	}
+}
@@ -0,0 +1,179 @@
+package verification_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	internaltest "github.com/skygeario/k8s-controller/internal/test"
+	"github.com/skygeario/k8s-controller/pkg/domain/verification"
+)
+
+const testToken = "bf46fcae092bcfdbbfb6900e0c343c4447cc284a98e0e3cf49df0470e90085ab"
+
+func newDNSServer(t *testing.T) *internaltest.DNSServer {
+	server, err := internaltest.NewDNSServer()
+	if err != nil {
+		t.Fatalf("cannot start DNS server: %s", err)
+	}
+	return server
+}
+
+func TestVerifyDomainUsingNameservers(t *testing.T) {
+	server := newDNSServer(t)
+	defer server.Close()
+	server.SetRecords("_skygear.my-app.test", "TXT", "other-token", testToken)
+
+	resolver := &verification.Resolver{
+		Nameservers: []string{server.Addr},
+		Timeout:     time.Second,
+	}
+	ctx := context.Background()
+
+	if err := verification.VerifyDomain(ctx, resolver, verification.RecordFormat{}, "my-app.test", testToken); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	err := verification.VerifyDomain(ctx, resolver, verification.RecordFormat{}, "my-app.test", "invalid-token")
+	if err == nil || err.Error() != "verification DNS record not found" {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := verification.VerifyDomain(ctx, resolver, verification.RecordFormat{}, "other-app.test", testToken); err == nil {
+		t.Errorf("expected lookup error")
+	}
+}
+
+func TestVerifyDomainUsingRecordFormat(t *testing.T) {
+	server := newDNSServer(t)
+	defer server.Close()
+	server.SetRecords("_acme-verify.my-app.test", "TXT", "acme-verification="+testToken)
+	server.SetRecords("_skygear.my-app.test", "TXT", testToken)
+
+	resolver := &verification.Resolver{
+		Nameservers: []string{server.Addr},
+		Timeout:     time.Second,
+	}
+	format := verification.RecordFormat{Prefix: "_acme-verify", TokenKey: "acme-verification"}
+	ctx := context.Background()
+
+	if err := verification.VerifyDomain(ctx, resolver, format, "my-app.test", testToken); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	format.TokenKey = ""
+	if err := verification.VerifyDomain(ctx, resolver, format, "my-app.test", testToken); err == nil {
+		t.Errorf("expected bare token to be rejected")
+	}
+	if err := (verification.RecordFormat{Prefix: ".invalid"}).Validate(); err == nil {
+		t.Errorf("expected invalid prefix to be rejected")
+	}
+}
+
+func TestRecordDomain(t *testing.T) {
+	cases := []struct {
+		domain   string
+		scope    domainv1beta1.VerificationRecordScope
+		expected string
+	}{
+		{"my-app.test", domainv1beta1.VerificationRecordScopeRoot, "my-app.test"},
+		{"app.corp.my-app.test", domainv1beta1.VerificationRecordScopeRoot, "my-app.test"},
+		{"my-app.test", domainv1beta1.VerificationRecordScopeExact, "my-app.test"},
+		{"App.Corp.my-app.test.", domainv1beta1.VerificationRecordScopeExact, "app.corp.my-app.test"},
+	}
+	for _, c := range cases {
+		recordDomain, err := verification.RecordDomain(c.domain, c.scope)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %s", c.domain, err)
+		} else if recordDomain != c.expected {
+			t.Errorf("expected record domain of %s (%s) to be %s, got %s", c.domain, c.scope, c.expected, recordDomain)
+		}
+	}
+}
+
+func TestVerifyDomainUsingAuthoritativeNameservers(t *testing.T) {
+	upstream := newDNSServer(t)
+	defer upstream.Close()
+	authoritative := newDNSServer(t)
+	defer authoritative.Close()
+
+	_, port, err := net.SplitHostPort(authoritative.Addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	upstream.SetRecords("my-app.test", "NS", "ns.my-app.test")
+	upstream.SetRecords("ns.my-app.test", "A", "127.0.0.1")
+	upstream.SetRecords("_skygear.my-app.test", "TXT", "stale-token")
+	authoritative.SetRecords("_skygear.my-app.test", "TXT", testToken)
+
+	resolver := &verification.Resolver{
+		Nameservers:        []string{upstream.Addr},
+		QueryAuthoritative: true,
+		AuthoritativePort:  port,
+		Timeout:            time.Second,
+	}
+	ctx := context.Background()
+
+	if err := verification.VerifyDomain(ctx, resolver, verification.RecordFormat{}, "my-app.test", testToken); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	resolver.QueryAuthoritative = false
+	if err := verification.VerifyDomain(ctx, resolver, verification.RecordFormat{}, "my-app.test", testToken); err == nil {
+		t.Errorf("expected stale record from upstream nameserver")
+	}
+}
+
+func TestResolverTimeout(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	resolver := &verification.Resolver{
+		Nameservers: []string{conn.LocalAddr().String()},
+		Timeout:     200 * time.Millisecond,
+	}
+
+	start := time.Now()
+	_, err = resolver.LookupTXT(context.Background(), "_skygear.my-app.test")
+	if err == nil {
+		t.Fatalf("expected timeout error")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("query is not timed out in time: %s", elapsed)
+	}
+}
+
+func TestCheckDNSRecords(t *testing.T) {
+	server := newDNSServer(t)
+	defer server.Close()
+	server.SetRecords("my-app.test", "A", "10.0.0.1")
+	server.SetRecords("www.my-app.test", "CNAME", "lb.example.test")
+	server.SetRecords("lb.example.test", "A", "10.0.0.2")
+	server.SetRecords("other.my-app.test", "A", "10.0.0.1", "10.0.0.3")
+
+	resolver := &verification.Resolver{
+		Nameservers: []string{server.Addr},
+		Timeout:     time.Second,
+	}
+	ctx := context.Background()
+
+	if err := verification.CheckDNSRecords(ctx, resolver, "my-app.test", []domainv1beta1.CustomDomainDNSRecord{
+		{Name: "@", Type: "A", Value: "10.0.0.1"},
+		{Name: "@", Type: "A", Value: "10.0.0.4"},
+	}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if err := verification.CheckDNSRecords(ctx, resolver, "www.my-app.test", []domainv1beta1.CustomDomainDNSRecord{
+		{Name: "www.my-app.test", Type: "CNAME", Value: "lb.example.test"},
+	}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if err := verification.CheckDNSRecords(ctx, resolver, "other.my-app.test", []domainv1beta1.CustomDomainDNSRecord{
+		{Name: "other.my-app.test", Type: "A", Value: "10.0.0.1"},
+	}); err == nil {
+		t.Errorf("expected unexpected address error")
+	}
+}
@@ -0,0 +1,77 @@
+package verification
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+)
+
+// CheckDNSRecords checks the domain resolves to the expected load balancer
+// DNS records.
+func CheckDNSRecords(ctx context.Context, resolver *Resolver, domain string, records []domainv1beta1.CustomDomainDNSRecord) error {
+	if resolver == nil {
+		resolver = defaultResolver
+	}
+
+	expectedIPs := map[string]struct{}{}
+	expectedCNAME := ""
+	for _, r := range records {
+		name := r.Name
+		if name == "@" {
+			name = domain
+		}
+		if normalizeDomain(name) != normalizeDomain(domain) {
+			continue
+		}
+
+		switch r.Type {
+		case "A", "AAAA":
+			ip := net.ParseIP(r.Value)
+			if ip == nil {
+				return fmt.Errorf("invalid load balancer IP address '%s'", r.Value)
+			}
+			expectedIPs[ip.String()] = struct{}{}
+		case "CNAME":
+			expectedCNAME = r.Value
+		}
+	}
+
+	if expectedCNAME != "" {
+		cname, err := resolver.LookupCNAME(ctx, domain)
+		if err != nil {
+			return fmt.Errorf("cannot lookup domain DNS records: %w", err)
+		}
+		if normalizeDomain(cname) == normalizeDomain(expectedCNAME) {
+			return nil
+		}
+
+		// CNAME may be flattened by DNS provider, compare the addresses instead.
+		addrs, err := resolver.LookupIPAddr(ctx, expectedCNAME)
+		if err != nil {
+			return fmt.Errorf("cannot lookup load balancer DNS records: %w", err)
+		}
+		for _, addr := range addrs {
+			expectedIPs[addr.IP.String()] = struct{}{}
+		}
+	}
+
+	if len(expectedIPs) == 0 {
+		return fmt.Errorf("no load balancer DNS records for the domain")
+	}
+
+	addrs, err := resolver.LookupIPAddr(ctx, domain)
+	if err != nil {
+		return fmt.Errorf("cannot lookup domain DNS records: %w", err)
+	}
+	if len(addrs) == 0 {
+		return fmt.Errorf("domain DNS records not found")
+	}
+	for _, addr := range addrs {
+		if _, ok := expectedIPs[addr.IP.String()]; !ok {
+			return fmt.Errorf("domain resolves to unexpected address %s", addr.IP)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package verification
+
+import (
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"golang.org/x/net/publicsuffix"
+)
+
+func ScopeOf(reg *domainv1beta1.CustomDomainRegistration) domainv1beta1.VerificationRecordScope {
+	if reg.Spec.VerificationRecordScope == nil {
+		return domainv1beta1.VerificationRecordScopeRoot
+	}
+	return *reg.Spec.VerificationRecordScope
+}
+
+// RecordDomain returns the domain verification DNS records of domain are
+// placed under.
+func RecordDomain(domain string, scope domainv1beta1.VerificationRecordScope) (string, error) {
+	if scope == domainv1beta1.VerificationRecordScopeExact {
+		return normalizeDomain(domain), nil
+	}
+	return publicsuffix.EffectiveTLDPlusOne(domain)
+}
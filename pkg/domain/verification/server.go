@@ -0,0 +1,103 @@
+package verification
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+)
+
+// TokenServer serves verification tokens of registrations using HTTP
+// verification method.
+type TokenServer struct {
+	KubeClient     client.Client
+	Addr           string
+	TokenGenerator func(key, nonce string) string
+}
+
+var _ manager.Runnable = &TokenServer{}
+
+func (s *TokenServer) Start(stop <-chan struct{}) error {
+	server := &http.Server{Addr: s.Addr, Handler: s}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.ListenAndServe()
+	}()
+
+	select {
+	case <-stop:
+		return server.Shutdown(context.Background())
+	case err := <-errCh:
+		return err
+	}
+}
+
+func (s *TokenServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.URL.Path, HTTPPathPrefix) {
+		http.NotFound(w, r)
+		return
+	}
+	nonce := strings.TrimPrefix(r.URL.Path, HTTPPathPrefix)
+
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	token, err := s.lookupToken(r.Context(), strings.ToLower(host), nonce)
+	if err != nil {
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	if token == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	_, _ = w.Write([]byte(token))
+}
+
+func (s *TokenServer) lookupToken(ctx context.Context, domainName string, nonce string) (string, error) {
+	var domain domainv1beta1.CustomDomain
+	if err := s.KubeClient.Get(ctx, types.NamespacedName{Name: domainName}, &domain); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	if domain.Spec.VerificationKey == nil {
+		return "", nil
+	}
+
+	// Anyone pointing the domain to us can pass HTTP verification, so the
+	// token is served only if the domain has no other registrations, even
+	// those using TXT verification; otherwise the HTTP registrant could
+	// claim a domain contested by others.
+	httpNamespace := ""
+	for _, ref := range domain.Spec.Registrations {
+		var reg domainv1beta1.CustomDomainRegistration
+		if err := s.KubeClient.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, &reg); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return "", err
+		}
+		if httpNamespace != "" || MethodOf(&reg) != domainv1beta1.VerificationMethodHTTP {
+			return "", nil
+		}
+		httpNamespace = reg.Namespace
+	}
+	if httpNamespace == "" || httpNamespace != nonce {
+		return "", nil
+	}
+
+	return s.TokenGenerator(*domain.Spec.VerificationKey, nonce), nil
+}
@@ -0,0 +1,123 @@
+package verification_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/verification"
+)
+
+const testKey = "domain-verification-key"
+
+func newTokenServer(methods map[string]domainv1beta1.VerificationMethod) (*httptest.Server, string) {
+	scheme := runtime.NewScheme()
+	_ = domainv1beta1.AddToScheme(scheme)
+
+	// Token server looks up domain by host name without port.
+	const domainName = "127.0.0.1"
+	key := testKey
+	domain := &domainv1beta1.CustomDomain{
+		ObjectMeta: metav1.ObjectMeta{Name: domainName},
+		Spec:       domainv1beta1.CustomDomainSpec{VerificationKey: &key},
+	}
+	objs := []runtime.Object{domain}
+	for namespace, method := range methods {
+		method := method
+		objs = append(objs, &domainv1beta1.CustomDomainRegistration{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: domainName},
+			Spec: domainv1beta1.CustomDomainRegistrationSpec{
+				DomainName:         domainName,
+				VerificationMethod: &method,
+			},
+		})
+		domain.Spec.Registrations = append(domain.Spec.Registrations, corev1.ObjectReference{
+			Namespace: namespace,
+			Name:      domainName,
+		})
+	}
+
+	server := httptest.NewServer(&verification.TokenServer{
+		KubeClient:     fake.NewFakeClientWithScheme(scheme, objs...),
+		TokenGenerator: verification.GenerateDomainToken,
+	})
+	return server, strings.TrimPrefix(server.URL, "http://")
+}
+
+func TestTokenServer(t *testing.T) {
+	server, _ := newTokenServer(map[string]domainv1beta1.VerificationMethod{
+		"app1": domainv1beta1.VerificationMethodHTTP,
+	})
+	defer server.Close()
+
+	get := func(path string) (int, string) {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode, string(body)
+	}
+
+	if status, body := get(verification.MakeHTTPPath("app1")); status != http.StatusOK ||
+		body != verification.GenerateDomainToken(testKey, "app1") {
+		t.Errorf("unexpected response: %d %q", status, body)
+	}
+	for _, path := range []string{
+		verification.MakeHTTPPath("app2"),
+		verification.MakeHTTPPath(""),
+		"/app1",
+	} {
+		if status, _ := get(path); status != http.StatusNotFound {
+			t.Errorf("unexpected status for %s: %d", path, status)
+		}
+	}
+}
+
+func TestVerifyHTTP(t *testing.T) {
+	const httpMethod = domainv1beta1.VerificationMethodHTTP
+	const txtMethod = domainv1beta1.VerificationMethodTXT
+
+	cases := []struct {
+		name    string
+		methods map[string]domainv1beta1.VerificationMethod
+		ok      bool
+	}{
+		{"single", map[string]domainv1beta1.VerificationMethod{"app1": httpMethod}, true},
+		{"multiple", map[string]domainv1beta1.VerificationMethod{"app1": httpMethod, "app2": httpMethod}, false},
+		{"mixed", map[string]domainv1beta1.VerificationMethod{"app1": httpMethod, "app2": txtMethod}, false},
+		{"none", map[string]domainv1beta1.VerificationMethod{"app1": txtMethod}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server, addr := newTokenServer(c.methods)
+			defer server.Close()
+
+			token := verification.GenerateDomainToken(testKey, "app1")
+			err := verification.VerifyHTTP(context.Background(), addr, "app1", token)
+			if (err == nil) != c.ok {
+				t.Errorf("unexpected verification result: %v", err)
+			}
+
+			if c.ok {
+				err := verification.VerifyHTTP(context.Background(), addr, "app1", "wrong-token")
+				if err == nil {
+					t.Error("expected verification to fail with wrong token")
+				}
+			}
+		})
+	}
+}